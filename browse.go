@@ -0,0 +1,413 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/ingordigia/pargolo/util"
+	"golang.org/x/term"
+)
+
+const browseHelp = "↑↓ move  → expand  ← collapse  / search  r reveal  e edit  d delete  q quit"
+
+// Browser is a full screen tree view of the parameter hierarchy, values are loaded one level at a time
+type Browser struct {
+	svc       ssmiface.SSMAPI
+	tree      *util.ParameterNode
+	loaded    map[string]bool
+	revealed  map[string]bool
+	cursor    int
+	offset    int
+	height    int
+	filter    string
+	searching bool
+	message   string
+	in        io.Reader
+	out       io.Writer
+}
+
+// BrowseParameters opens the browser on the parameters under path
+func BrowseParameters(path string) {
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	browser, err := NewBrowser(svc, path, os.Stdin, os.Stdout)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		log.Fatalln("browse needs a terminal")
+	}
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	err = browser.Run()
+	fmt.Print("\x1b[?25h\x1b[?1049l")
+	term.Restore(int(os.Stdin.Fd()), state)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// NewBrowser lists the parameter names under path and loads the values of its first level
+func NewBrowser(svc ssmiface.SSMAPI, path string, in io.Reader, out io.Writer) (*Browser, error) {
+	types, err := listParameterTypes(svc, path)
+	if err != nil {
+		return nil, err
+	}
+	b := &Browser{
+		svc:      svc,
+		tree:     util.NewParameterTree(path, types),
+		loaded:   make(map[string]bool),
+		revealed: make(map[string]bool),
+		height:   24,
+		in:       in,
+		out:      out,
+	}
+	b.load(b.tree.Path)
+	return b, nil
+}
+
+// listParameterTypes returns the names and types of the parameters under path without reading their values
+func listParameterTypes(svc ssmiface.SSMAPI, path string) (map[string]string, error) {
+	list, err := describeParameters(svc, path)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]string)
+	for _, metadata := range list {
+		types[metadata.Name] = metadata.Type
+	}
+	return types, nil
+}
+
+// Run draws the tree and handles the keys until the user quits
+func (b *Browser) Run() error {
+	for {
+		b.draw()
+		key, err := b.readKey()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !b.handle(key) {
+			return nil
+		}
+	}
+}
+
+func (b *Browser) selected(rows []util.TreeRow) *util.ParameterNode {
+	if b.cursor < 0 || b.cursor >= len(rows) {
+		return nil
+	}
+	return rows[b.cursor].Node
+}
+
+// handle applies a key, it returns false when the browser must be closed
+func (b *Browser) handle(key string) bool {
+	if b.searching {
+		switch key {
+		case "esc":
+			b.filter, b.searching = "", false
+		case "enter":
+			b.searching = false
+		case "backspace":
+			if runes := []rune(b.filter); len(runes) > 0 {
+				b.filter = string(runes[:len(runes)-1])
+			}
+		default:
+			if isPrintable(key) {
+				b.filter += key
+			}
+		}
+		b.cursor = 0
+		return true
+	}
+
+	rows := b.tree.Rows(b.filter)
+	node := b.selected(rows)
+	b.message = ""
+	switch key {
+	case "q", "ctrl-c":
+		return false
+	case "up", "k":
+		b.cursor--
+	case "down", "j":
+		b.cursor++
+	case "right", "l", "enter":
+		if node == nil {
+			break
+		}
+		if len(node.Children) > 0 {
+			node.Expanded = true
+			b.load(node.Path)
+		}
+		b.loadValue(node)
+	case "left", "h":
+		if node == nil {
+			break
+		}
+		if node.Expanded && len(node.Children) > 0 && b.filter == "" {
+			node.Expanded = false
+			break
+		}
+		parent := node.Path[:strings.LastIndex(node.Path, "/")]
+		for i, row := range rows {
+			if row.Node.Path == parent {
+				b.cursor = i
+			}
+		}
+	case "/":
+		b.searching = true
+	case "esc":
+		b.filter = ""
+	case "r":
+		if node != nil && node.Type == "SecureString" {
+			b.loadValue(node)
+			b.revealed[node.Path] = !b.revealed[node.Path]
+		}
+	case "e":
+		if node != nil && node.IsParameter() {
+			b.edit(node)
+		}
+	case "d":
+		if node != nil && node.IsParameter() {
+			b.delete(node)
+		}
+	}
+	return true
+}
+
+// load reads the values of the parameters directly under path
+func (b *Browser) load(path string) {
+	if b.loaded[path] {
+		return
+	}
+	if path == "" {
+		path = "/"
+	}
+	params, err := getParametersAtPath(b.svc, path, false)
+	if err != nil {
+		b.message = err.Error()
+		return
+	}
+	for _, param := range params {
+		node := b.tree.Find(param.Name)
+		if node == nil {
+			node = b.tree.Add(param.Name, param.Type)
+		}
+		if node != nil {
+			node.Type, node.Value, node.Loaded = param.Type, param.Value, true
+		}
+	}
+	b.loaded[strings.TrimSuffix(path, "/")] = true
+}
+
+func (b *Browser) loadValue(node *util.ParameterNode) {
+	if node.IsParameter() && !node.Loaded {
+		b.load(node.Path[:strings.LastIndex(node.Path, "/")])
+	}
+}
+
+func (b *Browser) edit(node *util.ParameterNode) {
+	b.loadValue(node)
+	initial := node.Value
+	if node.Type == "SecureString" && !b.revealed[node.Path] {
+		initial = ""
+	}
+	newValue, ok := b.readLine("New value: ", initial, node.Type == "SecureString")
+	if !ok || newValue == "" || newValue == node.Value {
+		b.message = "unchanged"
+		return
+	}
+	if !b.ask(fmt.Sprintf("Overwrite %s? [y/N] ", node.Path)) {
+		b.message = "cancelled"
+		return
+	}
+	version, err := putParameter(b.svc, node.Path, node.Type, newValue, true)
+	if err != nil {
+		b.message = err.Error()
+		return
+	}
+	node.Value, node.Loaded = newValue, true
+	b.message = fmt.Sprintf("%s set to version %d", node.Path, version)
+}
+
+func (b *Browser) delete(node *util.ParameterNode) {
+	if !b.ask(fmt.Sprintf("Delete %s? [y/N] ", node.Path)) {
+		b.message = "cancelled"
+		return
+	}
+	_, err := b.svc.DeleteParameter(&ssm.DeleteParameterInput{Name: aws.String(node.Path)})
+	if err != nil {
+		b.message = err.Error()
+		return
+	}
+	b.tree.Remove(node.Path)
+	b.message = node.Path + " deleted"
+}
+
+func (b *Browser) ask(question string) bool {
+	b.status(question)
+	key, err := b.readKey()
+	return err == nil && (key == "y" || key == "Y")
+}
+
+// readLine edits a line on the status bar, it returns false if it is cancelled with esc
+func (b *Browser) readLine(question string, line string, masked bool) (string, bool) {
+	for {
+		shown := line
+		if masked {
+			shown = strings.Repeat("*", len([]rune(line)))
+		}
+		b.status(question + shown)
+		key, err := b.readKey()
+		if err != nil {
+			return "", false
+		}
+		switch key {
+		case "enter":
+			return line, true
+		case "esc", "ctrl-c":
+			return "", false
+		case "backspace":
+			if runes := []rune(line); len(runes) > 0 {
+				line = string(runes[:len(runes)-1])
+			}
+		default:
+			if isPrintable(key) {
+				line += key
+			}
+		}
+	}
+}
+
+// readKey reads a key press, arrows and control keys are returned by name
+func (b *Browser) readKey() (string, error) {
+	buf := make([]byte, 64)
+	n, err := b.in.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	if n == 0 {
+		return "", errors.New("no input")
+	}
+	switch key := string(buf[:n]); key {
+	case "\x1b[A", "\x1bOA":
+		return "up", nil
+	case "\x1b[B", "\x1bOB":
+		return "down", nil
+	case "\x1b[C", "\x1bOC":
+		return "right", nil
+	case "\x1b[D", "\x1bOD":
+		return "left", nil
+	case "\x1b":
+		return "esc", nil
+	case "\r", "\n":
+		return "enter", nil
+	case "\x7f", "\b":
+		return "backspace", nil
+	case "\x03":
+		return "ctrl-c", nil
+	default:
+		return key, nil
+	}
+}
+
+func isPrintable(key string) bool {
+	for _, r := range key {
+		if r < ' ' || r == 0x7f {
+			return false
+		}
+	}
+	return key != ""
+}
+
+func (b *Browser) draw() {
+	width, height := 80, 24
+	if file, ok := b.out.(*os.File); ok {
+		if w, h, err := term.GetSize(int(file.Fd())); err == nil {
+			width, height = w, h
+		}
+	}
+	b.height = height
+
+	rows := b.tree.Rows(b.filter)
+	if b.cursor >= len(rows) {
+		b.cursor = len(rows) - 1
+	}
+	if b.cursor < 0 {
+		b.cursor = 0
+	}
+	listHeight := height - 1
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+listHeight {
+		b.offset = b.cursor - listHeight + 1
+	}
+
+	var screen strings.Builder
+	screen.WriteString("\x1b[H\x1b[2J")
+	for i := b.offset; i < len(rows) && i < b.offset+listHeight; i++ {
+		line := util.TruncateLine(b.formatRow(rows[i]), width)
+		if i == b.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		screen.WriteString(line + "\r\n")
+	}
+	fmt.Fprint(b.out, screen.String())
+
+	switch {
+	case b.searching:
+		b.status("/" + b.filter)
+	case b.message != "":
+		b.status(b.message)
+	case b.filter != "":
+		b.status(fmt.Sprintf("filter: %s (esc to clear)  %s", b.filter, browseHelp))
+	default:
+		b.status(fmt.Sprintf("%s (%d)  %s", b.tree.Name, b.tree.Count(), browseHelp))
+	}
+}
+
+func (b *Browser) status(text string) {
+	fmt.Fprintf(b.out, "\x1b[%d;1H\x1b[2K%s", b.height, text)
+}
+
+func (b *Browser) formatRow(row util.TreeRow) string {
+	node := row.Node
+	marker := "  "
+	if len(node.Children) > 0 {
+		marker = "▸ "
+		if node.Expanded || b.filter != "" {
+			marker = "▾ "
+		}
+	}
+	line := strings.Repeat("  ", row.Depth) + marker + node.Name
+	if len(node.Children) > 0 {
+		line += fmt.Sprintf(" (%d)", node.Count())
+	}
+	if node.IsParameter() {
+		shown := "…"
+		if node.Loaded {
+			shown = util.MaskValue(node.Type, node.Value)
+			if b.revealed[node.Path] {
+				shown = node.Value
+			}
+		}
+		line += " = " + strings.Replace(shown, "\n", " ", -1)
+	}
+	return line
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedKeys returns one key press per Read, then io.EOF
+type scriptedKeys []string
+
+func (k *scriptedKeys) Read(p []byte) (int, error) {
+	if len(*k) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, (*k)[0])
+	*k = (*k)[1:]
+	return n, nil
+}
+
+func newTestBrowser(t *testing.T, keys ...string) (*Browser, *memoryStore) {
+	store := newMemoryStore([][]string{
+		{"/staging/common/redis/endpoint", "String", "redis.staging.internal"},
+		{"/staging/dom/proj/webapp/port", "String", "8080"},
+		{"/staging/dom/proj/db/password", "SecureString", "old-secret"},
+	})
+	store.current("/staging/dom/proj/db/password").KeyID = "alias/pargolo"
+
+	script := scriptedKeys(keys)
+	browser, err := NewBrowser(store, "/staging", &script, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := browser.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return browser, store
+}
+
+func visiblePaths(b *Browser) []string {
+	var paths []string
+	for _, row := range b.tree.Rows(b.filter) {
+		paths = append(paths, row.Node.Path)
+	}
+	return paths
+}
+
+func TestBrowseExpandLoadsLevel(t *testing.T) {
+	b, _ := newTestBrowser(t, "j", "\r", "j", "\r")
+
+	assert.Equal(t, []string{
+		"/staging/common",
+		"/staging/dom",
+		"/staging/dom/proj",
+		"/staging/dom/proj/db",
+		"/staging/dom/proj/webapp",
+	}, visiblePaths(b))
+	assert.Equal(t, true, b.loaded["/staging/dom/proj"])
+	assert.Equal(t, false, b.loaded["/staging/dom/proj/db"])
+	assert.Equal(t, false, b.tree.Find("/staging/dom/proj/db/password").Loaded)
+}
+
+func TestBrowseSearchAndReveal(t *testing.T) {
+	b, _ := newTestBrowser(t, "/", "pass", "\r", "j", "j", "j", "r")
+
+	assert.Equal(t, "pass", b.filter)
+	assert.Equal(t, []string{"/staging/dom", "/staging/dom/proj", "/staging/dom/proj/db", "/staging/dom/proj/db/password"}, visiblePaths(b))
+
+	row := b.tree.Rows(b.filter)[3]
+	assert.Equal(t, true, b.revealed["/staging/dom/proj/db/password"])
+	assert.Equal(t, true, strings.HasSuffix(b.formatRow(row), "password = old-secret"))
+
+	b.revealed["/staging/dom/proj/db/password"] = false
+	assert.Equal(t, true, strings.HasSuffix(b.formatRow(row), "password = ********"))
+}
+
+func TestBrowseEditKeepsKey(t *testing.T) {
+	b, store := newTestBrowser(t, "/", "pass", "\r", "j", "j", "j", "e", "new-secret", "\r", "y")
+
+	current := store.current("/staging/dom/proj/db/password")
+	assert.Equal(t, "new-secret", current.Value)
+	assert.Equal(t, int64(2), current.Version)
+	assert.Equal(t, "alias/pargolo", current.KeyID)
+	assert.Equal(t, "new-secret", b.tree.Find("/staging/dom/proj/db/password").Value)
+}
+
+func TestBrowseEditCancelled(t *testing.T) {
+	_, store := newTestBrowser(t, "/", "port", "\r", "j", "j", "j", "e", "9090", "\r", "n")
+
+	assert.Equal(t, "8080", store.current("/staging/dom/proj/webapp/port").Value)
+}
+
+func TestBrowseDelete(t *testing.T) {
+	b, store := newTestBrowser(t, "\r", "j", "\r", "j", "d", "y")
+
+	assert.Nil(t, store.current("/staging/common/redis/endpoint"))
+	assert.Nil(t, b.tree.Find("/staging/common"))
+	assert.Equal(t, "/staging/common/redis/endpoint deleted", b.message)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ingordigia/pargolo/util"
+)
+
+var group string
+
+// config holds the defaults read from the .pargolo.yaml file
+var config = &util.Config{}
+
+// parseFlags parses the subcommand flags, then fills the flags not given on the command line with the configuration defaults
+func parseFlags(flagset *flag.FlagSet) {
+	flagset.Parse(os.Args[2:])
+
+	var err error
+	config, err = util.FindConfig()
+	if err != nil {
+		log.Fatalln("error reading "+util.ConfigFileName+":", err)
+	}
+	applyConfig(flagset, config)
+}
+
+func applyConfig(flagset *flag.FlagSet, config *util.Config) {
+	set := make(map[string]bool)
+	flagset.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	setDefault := func(name string, value string) {
+		if value != "" && !set[name] && flagset.Lookup(name) != nil {
+			flagset.Set(name, value)
+			set[name] = true
+		}
+	}
+
+	setDefault("env", config.Env)
+	setDefault("domain", config.Domain)
+	setDefault("project", config.Project)
+	if named, ok := config.Environments[env]; ok && env != "" {
+		setDefault("profile", named.Profile)
+		setDefault("region", named.Region)
+	}
+	setDefault("profile", config.Profile)
+	setDefault("region", config.Region)
+	if _, ok := os.LookupEnv("PARGOLO_PROTECTED_ENVS"); !ok {
+		setDefault("protected-envs", strings.Join(config.ProtectedEnvs, ","))
+	}
+
+	if group != "" {
+		selected, ok := config.Groups[group]
+		if !ok {
+			log.Fatalln("unknown group " + group)
+		}
+		setDefault("profiles", strings.Join(selected.Profiles, ","))
+		setDefault("regions", strings.Join(selected.Regions, ","))
+	}
+}
+
+// projectPath returns the path of the project parameters following the configured path template
+func projectPath(env string, domain string, project string) string {
+	return config.ProjectPath(env, domain, project)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// DeleteParametersFromCsv deletes the parameters named in the first column of a CSV, such as the report of stale.
+// When env is not empty rows targeting a different environment must be confirmed.
+func DeleteParametersFromCsv(filename string, env string, force bool) {
+	records, err := readCsvRecords(filename)
+	if err != nil {
+		log.Fatalln("error reading csv:", err)
+	}
+
+	var names []string
+	for _, row := range records {
+		if len(row) > 0 && row[0] != "" {
+			names = append(names, row[0])
+		}
+	}
+	if len(names) == 0 {
+		println("no parameters to delete in " + filename)
+		return
+	}
+
+	if !CheckTargetEnvironments(records, env) {
+		log.Fatalln("delete aborted")
+	}
+	for _, name := range names {
+		println("DELETE  - " + name)
+	}
+	if !force && !confirm(fmt.Sprintf("Delete %d parameters? [y/N] ", len(names))) {
+		log.Fatalln("delete aborted")
+	}
+
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	deleted, invalid, err := deleteParameters(svc, names)
+	for _, name := range deleted {
+		println("DELETED - " + name)
+	}
+	for _, name := range invalid {
+		println("can't find parameter " + name)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// deleteParameters deletes the parameters in batches of 10 names per request,
+// the names not found are returned as invalid
+func deleteParameters(svc ssmiface.SSMAPI, names []string) (deleted []string, invalid []string, err error) {
+	for start := 0; start < len(names); start += maxNamesPerRequest {
+		end := start + maxNamesPerRequest
+		if end > len(names) {
+			end = len(names)
+		}
+		output, err := svc.DeleteParameters(&ssm.DeleteParametersInput{
+			Names: aws.StringSlice(names[start:end]),
+		})
+		if err != nil {
+			return deleted, invalid, err
+		}
+		deleted = append(deleted, aws.StringValueSlice(output.DeletedParameters)...)
+		invalid = append(invalid, aws.StringValueSlice(output.InvalidParameters)...)
+	}
+	return deleted, invalid, nil
+}
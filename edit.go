@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ingordigia/pargolo/util"
+)
+
+// EditParameters downloads the parameters under path into a temporary file, opens it with the editor and,
+// after confirmation, applies the differences. Parameters modified by someone else in the meantime abort the changes.
+// The versions are read again right before writing, a change made after that is only detected once the value is written.
+func EditParameters(path string, format string) {
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	original, err := getParametersByPath(svc, path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	edited, err := editRecords(original.Records(), format)
+	if err != nil {
+		log.Fatalln("error editing parameters:", err)
+	}
+	for _, row := range edited {
+		if !strings.HasPrefix(row[0], strings.TrimSuffix(path, "/")+"/") {
+			log.Fatalf("%s is outside of %s\n", row[0], path)
+		}
+		if !util.ParameterTypes[row[1]] {
+			log.Fatalf("%s has invalid type %s\n", row[0], row[1])
+		}
+	}
+
+	results := util.DiffRecords(original.Records(), edited)
+	if len(results) == 0 {
+		fmt.Println("no changes")
+		return
+	}
+	util.WriteValidationReport(os.Stdout, "text", results, false)
+	if !confirm(fmt.Sprintf("Apply %d changes to %s? [y/N] ", len(results), path)) {
+		log.Fatalln("aborted")
+	}
+
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	current, _, err := getParametersByNames(svc, names)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	conflict := false
+	for _, result := range results {
+		before, existed := original[result.Name]
+		now, exists := current[result.Name]
+		if existed != exists || (exists && before.Version != now.Version) {
+			println(result.Name + " was modified while editing")
+			conflict = true
+		}
+	}
+	if conflict {
+		log.Fatalln("no changes applied, edit again to start from the current values")
+	}
+
+	for _, result := range results {
+		switch result.Action {
+		case util.ActionDelete:
+			err = DeleteParameter(result.Name)
+		case util.ActionCreate:
+			_, err = PutParameter(result.Name, result.Type, result.NewValue, false)
+		default:
+			var version int64
+			version, err = PutParameter(result.Name, result.Type, result.NewValue, true)
+			if err == nil && version != original[result.Name].Version+1 {
+				err = fmt.Errorf("was modified by someone else right before the write: the value was written anyway as version %d, check the previous versions", version)
+			}
+		}
+		if err != nil {
+			log.Fatalln(result.Name+":", err)
+		}
+		println(result.Status() + " " + result.Name)
+	}
+}
+
+// editRecords writes the records in a private temporary directory and opens them with the editor,
+// the directory and any backup file left by the editor are wiped before returning
+func editRecords(records [][]string, format string) ([][]string, error) {
+	data, err := util.EncodeParameters(format, records)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "pargolo-edit-")
+	if err != nil {
+		return nil, err
+	}
+	defer wipeDir(dir)
+
+	filename := filepath.Join(dir, "parameters."+format)
+	if err := ioutil.WriteFile(filename, data, 0600); err != nil {
+		return nil, err
+	}
+
+	editor := strings.Fields(editorCommand())
+	cmd := exec.Command(editor[0], append(editor[1:], filename)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	data, err = ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return util.DecodeParameters(format, data)
+}
+
+// editorCommand returns the editor from VISUAL or EDITOR, defaulting to vi
+func editorCommand() string {
+	for _, variable := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(variable)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// wipeDir overwrites the regular files in dir with zeros before removing it, so that no secret is left on disk
+func wipeDir(dir string) {
+	filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.OpenFile(name, os.O_WRONLY, 0)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+		file.Write(make([]byte, info.Size()))
+		file.Sync()
+		return nil
+	})
+	if err := os.RemoveAll(dir); err != nil {
+		println("error removing " + dir + ": " + err.Error())
+	}
+}
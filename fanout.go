@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/ingordigia/pargolo/util"
+)
+
+var profiles, regions string
+
+// maxConcurrentTargets limits the number of accounts and regions searched at the same time
+const maxConcurrentTargets = 8
+
+// TaggedParameter is a parameter found in a specific account and region
+type TaggedParameter struct {
+	Account string
+	Region  string
+	SystemsManagerParameter
+}
+
+// fanOutTargets returns one target for each pair of the -profiles and -regions lists,
+// falling back to -profile and -region when a list is empty
+func fanOutTargets() []awsTarget {
+	profileList := util.SplitList(profiles)
+	if len(profileList) == 0 {
+		profileList = []string{profile}
+	}
+	regionList := util.SplitList(regions)
+	if len(regionList) == 0 {
+		regionList = []string{region}
+	}
+
+	var targets []awsTarget
+	for _, p := range profileList {
+		for _, r := range regionList {
+			targets = append(targets, awsTarget{Profile: p, Region: r})
+		}
+	}
+	return targets
+}
+
+// FanOut runs search concurrently against every target and tags each result with its account and region.
+// Targets that fail are reported and skipped.
+func FanOut(targets []awsTarget, search func(svc ssmiface.SSMAPI) (SystemsManagerParameters, error)) []TaggedParameter {
+	var results []TaggedParameter
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan bool, maxConcurrentTargets)
+
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target awsTarget) {
+			defer wg.Done()
+			slots <- true
+			defer func() { <-slots }()
+
+			svc, err := newSSMClientFor(target)
+			if err == nil {
+				var params SystemsManagerParameters
+				params, err = search(svc)
+				if err == nil {
+					account := accountOf(target)
+					lock.Lock()
+					for _, param := range params {
+						results = append(results, TaggedParameter{Account: account, Region: target.Region, SystemsManagerParameter: *param})
+					}
+					lock.Unlock()
+					return
+				}
+			}
+			println(target.String() + ": " + err.Error())
+		}(target)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Account != results[j].Account {
+			return results[i].Account < results[j].Account
+		}
+		if results[i].Region != results[j].Region {
+			return results[i].Region < results[j].Region
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// FanOutParametersByPath retrieves the parameters under path from every target.
+func FanOutParametersByPath(targets []awsTarget, path string, recursive bool) {
+	results := FanOut(targets, func(svc ssmiface.SSMAPI) (SystemsManagerParameters, error) {
+		params, err := getParametersByLabel(svc, path, label)
+		if err == nil && recursive {
+			resolveCommonValues(svc, params)
+		}
+		return params, err
+	})
+	writeTaggedParameters(results, fmt.Sprintf("searchbypath-%s-%s", output, time.Now().UTC().Format("20060102150405")))
+}
+
+// FanOutParametersByValue scrapes every target searching for the parameters with a specific value.
+func FanOutParametersByValue(targets []awsTarget, targetvalue string, filterpath string) {
+	results := FanOut(targets, func(svc ssmiface.SSMAPI) (SystemsManagerParameters, error) {
+		params, err := getParametersByPath(svc, "/")
+		for name, param := range params {
+			if param.Value != targetvalue || !strings.HasPrefix(name, filterpath) {
+				delete(params, name)
+			}
+		}
+		return params, err
+	})
+	if len(results) == 0 {
+		println("can't find any parameter with value " + targetvalue)
+	}
+	writeTaggedParameters(results, fmt.Sprintf("searchbyvalue-%s-%s", output, time.Now().UTC().Format("20060102150405")))
+}
+
+// writeTaggedParameters prints the results to the shell or, with -output, writes them to a CSV with account and region columns
+func writeTaggedParameters(results []TaggedParameter, fileName string) {
+	if output == "" {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		for _, result := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.Account, result.Region, result.Type, result.Name, result.Value)
+		}
+		w.Flush()
+		return
+	}
+
+	records := [][]string{}
+	for _, result := range results {
+		records = append(records, []string{result.Account, result.Region, result.Name, result.Type, result.Value})
+	}
+
+	file, err := os.Create(getFilePath(fileName, "csv"))
+	if err != nil {
+		log.Fatalln("error writing csv:", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+
+	w.WriteAll(records) // calls Flush internally
+
+	if err := w.Error(); err != nil {
+		log.Fatalln("error writing csv:", err)
+	}
+}
+
+// addFanOutFlags registers the multi-account and multi-region flags on a subcommand
+func addFanOutFlags(flagset *flag.FlagSet) {
+	flagset.StringVar(&profiles, "profiles", "", "(optional) Comma separated AWS profiles to search concurrently")
+	flagset.StringVar(&regions, "regions", "", "(optional) Comma separated AWS regions to search concurrently")
+	flagset.StringVar(&group, "group", "", "(optional) Named group of profiles and regions defined in "+util.ConfigFileName)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/ingordigia/pargolo/util"
+)
+
+var label string
+
+// LabelParameters attaches the label to the current version of every parameter under path,
+// a label already on an older version of a parameter is moved. A failure doesn't stop the others,
+// the parameters left without the label are listed at the end.
+func LabelParameters(path string, label string) {
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	list, err := describeParameters(svc, path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	util.SortMetadata(list, "name", false)
+
+	failed := labelParameters(svc, list, label)
+	println(fmt.Sprintf("%d parameters labeled %s", len(list)-len(failed), label))
+	if len(failed) > 0 {
+		println(fmt.Sprintf("%d parameters not labeled:", len(failed)))
+		for _, name := range failed {
+			println("- " + name)
+		}
+		os.Exit(1)
+	}
+}
+
+// labelParameters labels the version of every parameter in list and returns the names it failed to label
+func labelParameters(svc ssmiface.SSMAPI, list []util.ParameterMetadata, label string) (failed []string) {
+	for _, metadata := range list {
+		output, err := svc.LabelParameterVersion(&ssm.LabelParameterVersionInput{
+			Name:             aws.String(metadata.Name),
+			ParameterVersion: aws.Int64(metadata.Version),
+			Labels:           aws.StringSlice([]string{label}),
+		})
+		if err == nil && len(output.InvalidLabels) > 0 {
+			err = fmt.Errorf("invalid label %s", aws.StringValue(output.InvalidLabels[0]))
+		}
+		if err != nil {
+			println("FAILED  - " + metadata.Name + ": " + err.Error())
+			failed = append(failed, metadata.Name)
+			continue
+		}
+		println(fmt.Sprintf("LABELED - %s version %d", metadata.Name, metadata.Version))
+	}
+	return failed
+}
+
+// GetParametersByLabel retrieves the versions labeled with label of the parameters under path,
+// parameters without the label are skipped. With an empty label the current versions are retrieved.
+func GetParametersByLabel(path string, label string) (params SystemsManagerParameters, err error) {
+	svc, err := NewSSMClient()
+	if err != nil {
+		return nil, err
+	}
+	return getParametersByLabel(svc, path, label)
+}
+
+func getParametersByLabel(svc ssmiface.SSMAPI, path string, label string) (params SystemsManagerParameters, err error) {
+	if label == "" {
+		return getParametersByPath(svc, path)
+	}
+
+	list, err := describeParameters(svc, path)
+	if err != nil {
+		return nil, err
+	}
+	var selectors []string
+	for _, metadata := range list {
+		selectors = append(selectors, util.LabelSelector(metadata.Name, label))
+	}
+	params, invalid, err := getParametersByNames(svc, selectors)
+	for _, selector := range invalid {
+		println(strings.TrimSuffix(selector, ":"+label) + " has no version labeled " + label)
+	}
+	return params, err
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"encoding/csv"
 	"errors"
 	"flag"
@@ -8,13 +9,16 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/ingordigia/pargolo/util"
+	"golang.org/x/term"
 )
 
 // Parameters is  a map of parameter names and values
@@ -22,24 +26,68 @@ type Parameters map[string]string
 
 // SystemsManagerParameter defines an AWS Systems Manager Parameter
 type SystemsManagerParameter struct {
-	Name  string
-	Type  string
-	Value string
+	Name    string
+	Type    string
+	Value   string
+	Version int64
 }
 
 // SystemsManagerParameters is  a map of parameter names and SystemsManagerParameter objects
 type SystemsManagerParameters map[string]*SystemsManagerParameter
 
-var profile, path, output, input, value, env, domain, filter, project string
-var overwrite, recursive bool
+// Names returns the parameter names in alphabetical order
+func (params SystemsManagerParameters) Names() []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Records returns the parameters as name,type,value CSV records sorted by name
+func (params SystemsManagerParameters) Records() [][]string {
+	records := [][]string{}
+	for _, name := range params.Names() {
+		records = append(records, []string{name, params[name].Type, params[name].Value})
+	}
+	return records
+}
+
+var profile, path, output, input, value, env, domain, filter, project, schema, tier, protectedEnvs, confirmEnv, format, secureRules, naming, separator, ignoreKeys, ignoreKeysFile, layers, envs, name, paramType, valueFile, fileFormat, columns, sortBy, olderThan, dir, requireCMK string
+var overwrite, recursive, allowCrossEnv, withDefaults, force, reveal, raw, tree, reverse bool
+var ifVersion int64
+var depth int
 var searchbypath *flag.FlagSet
 var upload *flag.FlagSet
 var searchbyvalue *flag.FlagSet
 var export *flag.FlagSet
 var validate *flag.FlagSet
 var initialize *flag.FlagSet
+var lint *flag.FlagSet
+var fmtcsv *flag.FlagSet
+var resolve *flag.FlagSet
+var matrix *flag.FlagSet
+var get *flag.FlagSet
+var set *flag.FlagSet
+var edit *flag.FlagSet
+var browse *flag.FlagSet
+var ls *flag.FlagSet
+var stale *flag.FlagSet
+var scan *flag.FlagSet
+var audit *flag.FlagSet
+var generate *flag.FlagSet
+var rotate *flag.FlagSet
+var labelcmd *flag.FlagSet
+var deletecmd *flag.FlagSet
 var allparams = make(map[string]*SystemsManagerParameter)
 
+// exitCodeBlocking is returned by validate when the CSV would overwrite or damage existing parameters
+const exitCodeBlocking = 3
+
+// maxNamesPerRequest is the maximum number of names accepted by a single GetParameters request
+const maxNamesPerRequest = 10
+
 // PrintMapToShell prints the parameters map to the shell standard Output
 func PrintMapToShell(params SystemsManagerParameters) {
 	maxKeyLength := 0
@@ -55,58 +103,102 @@ func PrintMapToShell(params SystemsManagerParameters) {
 			}
 		}
 	}
-	for _, value := range params {
+	for _, name := range params.Names() {
+		value := params[name]
 		println(value.Type + strings.Repeat(" ", maxTypeLenght+1-len(value.Type)) + value.Name + strings.Repeat(" ", maxKeyLength+1-len(value.Name)) + value.Value)
 	}
 }
 
-// CreateSession returns a new AWS session
-func CreateSession() (sess *session.Session, err error) {
-
-	awsRegion := aws.String("eu-west-1") // example "eu-west-1"   EU (Ireland)
-	if profile != "" {
-		os.Setenv("AWS_PROFILE", profile)
+// PrintTreeToShell prints the parameters under root as a tree to the shell standard Output,
+// lines are truncated to the terminal width
+func PrintTreeToShell(root string, params SystemsManagerParameters) {
+	types := make(map[string]string)
+	for name, param := range params {
+		types[name] = param.Type
+	}
+	hierarchy := util.NewParameterTree(root, types)
+	for name, param := range params {
+		if node := hierarchy.Find(name); node != nil {
+			node.Value = param.Value
+		}
 	}
 
-	sess, err = session.NewSession(&aws.Config{
-		Region: awsRegion,
-	})
-
-	return sess, err
+	options := util.TreeOptions{Depth: depth, Reveal: reveal}
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		options.Width = width
+	}
+	if err := util.WriteTree(os.Stdout, hierarchy, options); err != nil {
+		log.Fatalln(err)
+	}
 }
 
 // SetParameter sets a parameter on parameter store, paramType can be one of these: String, StringList, SecureString
 func SetParameter(paramName string, paramType string, paramValue string, overwrite bool) (err error) {
-	sess, err := CreateSession()
+	_, err = PutParameter(paramName, paramType, paramValue, overwrite)
+	return err
+}
+
+// PutParameter sets a parameter on parameter store and returns its new version
+func PutParameter(paramName string, paramType string, paramValue string, overwrite bool) (version int64, err error) {
+	svc, err := NewSSMClient()
 	if err != nil {
-		return err
+		return 0, err
 	}
+	return putParameter(svc, paramName, paramType, paramValue, overwrite)
+}
 
-	svc := ssm.New(sess)
-
+// putParameter sets a parameter, an overwritten SecureString keeps its KMS key instead of falling back to the AWS managed key
+func putParameter(svc ssmiface.SSMAPI, paramName string, paramType string, paramValue string, overwrite bool) (version int64, err error) {
 	input := &ssm.PutParameterInput{
 		Name:      aws.String(paramName),
 		Type:      aws.String(paramType),
 		Value:     aws.String(paramValue),
 		Overwrite: aws.Bool(overwrite),
 	}
-	_, err = svc.PutParameter(input)
+	if overwrite && paramType == "SecureString" {
+		keyID, err := keyIDOf(svc, paramName)
+		if err != nil {
+			return 0, err
+		}
+		if keyID != "" {
+			input.KeyId = aws.String(keyID)
+		}
+	}
+	output, err := svc.PutParameter(input)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return *output.Version, nil
+}
+
+// keyIDOf returns the KMS key of an existing SecureString parameter, or an empty string if the parameter doesn't exist
+func keyIDOf(svc ssmiface.SSMAPI, paramName string) (string, error) {
+	output, err := svc.DescribeParameters(&ssm.DescribeParametersInput{
+		ParameterFilters: []*ssm.ParameterStringFilter{{
+			Key:    aws.String("Name"),
+			Option: aws.String("Equals"),
+			Values: aws.StringSlice([]string{paramName}),
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, par := range output.Parameters {
+		if aws.StringValue(par.Name) == paramName {
+			return aws.StringValue(par.KeyId), nil
+		}
+	}
+	return "", nil
 }
 
 // DeleteParameter deletes a parameter on parameter store
 func DeleteParameter(paramName string) (err error) {
-	sess, err := CreateSession()
+	svc, err := NewSSMClient()
 	if err != nil {
 		return err
 	}
 
-	svc := ssm.New(sess)
-
 	input := &ssm.DeleteParameterInput{
 		Name: aws.String(paramName),
 	}
@@ -118,14 +210,12 @@ func DeleteParameter(paramName string) (err error) {
 	return nil
 }
 
-// GetParameterByName deletes a parameter on parameter store
+// GetParameterByName retrieves a parameter from parameter store
 func GetParameterByName(paramName string) (param SystemsManagerParameter, err error) {
-	sess, err := CreateSession()
+	svc, err := NewSSMClient()
 	if err != nil {
 		return param, err
 	}
-
-	svc := ssm.New(sess)
 	var output *ssm.GetParameterOutput
 
 	input := &ssm.GetParameterInput{
@@ -136,11 +226,79 @@ func GetParameterByName(paramName string) (param SystemsManagerParameter, err er
 	if err != nil {
 		return param, err
 	}
-	param = SystemsManagerParameter{Name: *output.Parameter.Name, Type: *output.Parameter.Type, Value: *output.Parameter.Value}
+	param = SystemsManagerParameter{Name: *output.Parameter.Name, Type: *output.Parameter.Type, Value: *output.Parameter.Value, Version: *output.Parameter.Version}
 
 	return param, nil
 }
 
+// GetParametersByNames retrieves a set of parameters in batches of 10 names per request,
+// returning the parameters found and the names that do not exist.
+func GetParametersByNames(paramNames []string) (params SystemsManagerParameters, invalid []string, err error) {
+	svc, err := NewSSMClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return getParametersByNames(svc, paramNames)
+}
+
+func getParametersByNames(svc ssmiface.SSMAPI, paramNames []string) (params SystemsManagerParameters, invalid []string, err error) {
+	params = make(map[string]*SystemsManagerParameter)
+
+	unique := make(map[string]bool)
+	var names []string
+	for _, name := range paramNames {
+		if !unique[name] {
+			unique[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for start := 0; start < len(names); start += maxNamesPerRequest {
+		end := start + maxNamesPerRequest
+		if end > len(names) {
+			end = len(names)
+		}
+		input := &ssm.GetParametersInput{
+			Names:          aws.StringSlice(names[start:end]),
+			WithDecryption: aws.Bool(true),
+		}
+		output, err := svc.GetParameters(input)
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "ValidationException" {
+			// a single malformed name rejects the whole batch, retry its names one by one to keep the valid ones
+			output, err = getParametersOneByOne(svc, names[start:end])
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, par := range output.Parameters {
+			params[*par.Name] = &SystemsManagerParameter{Name: *par.Name, Type: *par.Type, Value: *par.Value, Version: aws.Int64Value(par.Version)}
+		}
+		invalid = append(invalid, aws.StringValueSlice(output.InvalidParameters)...)
+	}
+	return params, invalid, nil
+}
+
+// getParametersOneByOne reads the names with a request each, the names rejected as malformed are returned as invalid
+func getParametersOneByOne(svc ssmiface.SSMAPI, names []string) (*ssm.GetParametersOutput, error) {
+	ret := &ssm.GetParametersOutput{}
+	for _, name := range names {
+		output, err := svc.GetParameters(&ssm.GetParametersInput{
+			Names:          aws.StringSlice([]string{name}),
+			WithDecryption: aws.Bool(true),
+		})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "ValidationException" {
+			ret.InvalidParameters = append(ret.InvalidParameters, aws.String(name))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret.Parameters = append(ret.Parameters, output.Parameters...)
+		ret.InvalidParameters = append(ret.InvalidParameters, output.InvalidParameters...)
+	}
+	return ret, nil
+}
+
 // GetParametersByValue scrape the entire parameter store searching for all keys with a specific value
 func GetParametersByValue(paramValue string) (params SystemsManagerParameters, err error) {
 	if len(allparams) == 0 {
@@ -165,12 +323,25 @@ func GetParametersByValue(paramValue string) (params SystemsManagerParameters, e
 
 // GetParametersByPath retrieves the parameter from the AWS System Manager Parameter Store starting from the initial path recursively.
 func GetParametersByPath(path string) (params SystemsManagerParameters, err error) {
-	sess, err := CreateSession()
+	svc, err := NewSSMClient()
 	if err != nil {
 		return nil, err
 	}
+	return getParametersByPath(svc, path)
+}
+
+func getParametersByPath(svc ssmiface.SSMAPI, path string) (params SystemsManagerParameters, err error) {
+	return getParametersAtPath(svc, path, true)
+}
+
+// getParametersAtPath retrieves the parameters under path, only the direct children unless recursive
+func getParametersAtPath(svc ssmiface.SSMAPI, path string, recursive bool) (params SystemsManagerParameters, err error) {
+	return readParametersAtPath(svc, path, recursive, true)
+}
+
+// readParametersAtPath retrieves the parameters under path, without decrypt SecureString values are left encrypted
+func readParametersAtPath(svc ssmiface.SSMAPI, path string, recursive bool, decrypt bool) (params SystemsManagerParameters, err error) {
 	params = make(map[string]*SystemsManagerParameter)
-	svc := ssm.New(sess)
 
 	var output *ssm.GetParametersByPathOutput
 	var nextToken *string
@@ -178,8 +349,8 @@ func GetParametersByPath(path string) (params SystemsManagerParameters, err erro
 		input := &ssm.GetParametersByPathInput{
 			MaxResults:     aws.Int64(10),
 			Path:           aws.String(path),
-			Recursive:      aws.Bool(true),
-			WithDecryption: aws.Bool(true),
+			Recursive:      aws.Bool(recursive),
+			WithDecryption: aws.Bool(decrypt),
 			NextToken:      nextToken,
 		}
 		output, err = svc.GetParametersByPath(input)
@@ -188,39 +359,151 @@ func GetParametersByPath(path string) (params SystemsManagerParameters, err erro
 		}
 		nextToken = output.NextToken
 		for _, par := range output.Parameters {
-			params[*par.Name] = &SystemsManagerParameter{Name: *par.Name, Type: *par.Type, Value: *par.Value}
+			params[*par.Name] = &SystemsManagerParameter{Name: *par.Name, Type: *par.Type, Value: *par.Value, Version: aws.Int64Value(par.Version)}
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
 	return params, nil
 }
 
+// describeParameters retrieves the metadata of the parameters under path, values are not read
+// so that kms:Decrypt permission is not needed
+func describeParameters(svc ssmiface.SSMAPI, path string) (list []util.ParameterMetadata, err error) {
+	var output *ssm.DescribeParametersOutput
+	var nextToken *string
+	for output == nil || nextToken != nil {
+		input := &ssm.DescribeParametersInput{
+			MaxResults: aws.Int64(50),
+			NextToken:  nextToken,
+		}
+		if path != "/" {
+			input.ParameterFilters = []*ssm.ParameterStringFilter{{
+				Key:    aws.String("Path"),
+				Option: aws.String("Recursive"),
+				Values: aws.StringSlice([]string{path}),
+			}}
+		}
+		output, err = svc.DescribeParameters(input)
+		if err != nil {
+			return nil, err
+		}
+		nextToken = output.NextToken
+		for _, par := range output.Parameters {
+			list = append(list, util.ParameterMetadata{
+				Name:             aws.StringValue(par.Name),
+				Type:             aws.StringValue(par.Type),
+				Tier:             aws.StringValue(par.Tier),
+				Version:          aws.Int64Value(par.Version),
+				LastModifiedDate: aws.TimeValue(par.LastModifiedDate),
+				LastModifiedUser: aws.StringValue(par.LastModifiedUser),
+				KeyID:            aws.StringValue(par.KeyId),
+			})
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return list, nil
+}
+
+// ListParameters prints the metadata of the parameters under path sorted by a column
+func ListParameters(path string, columns []string, sortBy string, reverse bool, format string) {
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	list, err := describeParameters(svc, path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	util.SortMetadata(list, sortBy, reverse)
+	if err := util.WriteMetadata(os.Stdout, format, list, columns); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// StaleParameters writes a CSV of the parameters under path not modified for the given age that are either
+// common parameters no value points to or parameters of projects with no recent change.
+// References are searched in the String and StringList values of the whole parameter store, which is read without decryption.
+func StaleParameters(path string, age time.Duration, outputfile string) {
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	list, err := describeParameters(svc, path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	params, err := readParametersAtPath(svc, "/", true, false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	references := make(map[string]bool)
+	for _, param := range params {
+		if param.Type != "SecureString" && strings.Contains(param.Value, "/common/") {
+			references[param.Value] = true
+		}
+	}
+
+	stale := util.FindStaleParameters(list, references, time.Now().Add(-age), config.ProjectDepth())
+
+	w := os.Stdout
+	if outputfile != "" {
+		w, err = os.Create(getFilePath(outputfile, "csv"))
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer w.Close()
+	}
+	if err := util.WriteStaleReport(w, stale); err != nil {
+		log.Fatalln("error writing csv:", err)
+	}
+}
+
+// ScanReferences searches the source and config files under dir for references to the parameters under path,
+// then reports the referenced names missing from the store and the parameters never referenced.
+func ScanReferences(dir string, path string, format string) (missing []util.Reference) {
+	references, err := util.ScanDir(dir, path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	list, err := describeParameters(svc, path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	var names []string
+	for _, metadata := range list {
+		names = append(names, metadata.Name)
+	}
+
+	missing, unused := util.CompareReferences(references, names)
+	if err := util.WriteScanReport(os.Stdout, format, missing, unused); err != nil {
+		log.Fatalln(err)
+	}
+	return missing
+}
+
 // DownloadParametersByPath retrieves the parameter from the AWS System Manager Parameter Store.
 func DownloadParametersByPath(path string, recursive bool) {
-	params, err := GetParametersByPath(path)
+	params, err := GetParametersByLabel(path, label)
 	if err != nil {
 		println(err.Error())
 	}
 
-	records := [][]string{}
-
-	for key, value := range params {
-		if strings.Contains(value.Value, "/common/") && recursive {
-			common, err := GetParameterByName(value.Value)
-			if err != nil {
-				println(err.Error())
-			} else {
-				param := params[key]
-				param.Value = common.Value
-				params[key] = param
-			}
+	if recursive {
+		svc, err := NewSSMClient()
+		if err != nil {
+			log.Fatalln(err)
 		}
+		resolveCommonValues(svc, params)
 	}
 
 	if output != "" {
-		for key, value := range params {
-			records = append(records, []string{key, value.Type, value.Value})
-		}
+		records := params.Records()
 		fileName := fmt.Sprintf("searchbypath-%s-%s", output, time.Now().UTC().Format("20060102150405"))
 
 		file, err := os.Create(getFilePath(fileName, "csv"))
@@ -236,34 +519,63 @@ func DownloadParametersByPath(path string, recursive bool) {
 		if err := w.Error(); err != nil {
 			log.Fatalln("error writing csv:", err)
 		}
+	} else if tree {
+		PrintTreeToShell(path, params)
 	} else {
 		PrintMapToShell(params)
 	}
 }
 
 // UploadParametersFromCsv read parameters from CSV and write them to the AWS System Manager Parameter Store.
-func UploadParametersFromCsv(filename string, overwrite bool) {
+// When env is not empty rows targeting a different environment must be confirmed.
+func UploadParametersFromCsv(filename string, env string, overwrite bool) {
 
-	// Open the file
-	csvfile, err := os.Open(getFilePath(filename, "csv"))
+	records, err := readCsvRecords(filename)
 	if err != nil {
 		println(err.Error())
 	}
 
-	// Parse the file
-	r := csv.NewReader(csvfile)
-
-	records, err := r.ReadAll()
-	if err != nil {
-		println(err.Error())
+	if !CheckTargetEnvironments(records, env) {
+		log.Fatalln("upload aborted")
 	}
 
 	if len(records) > 0 {
-		for _, row := range records {
-			err := SetParameter(row[0], row[1], row[2], overwrite)
+		svc, err := NewSSMClient()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		uploadRecords(svc, records, overwrite)
+	}
+}
+
+// uploadRecords writes the CSV rows, replacing !generate placeholders with random secrets.
+// Generated values are only written to new parameters, the existing ones are reported as skipped.
+func uploadRecords(svc ssmiface.SSMAPI, records [][]string, overwrite bool) {
+	for i, row := range records {
+		if len(row) < 3 {
+			println(fmt.Sprintf("row %d: expected 3 columns: name,type,value", i+1))
+			continue
+		}
+		paramValue, rowOverwrite := row[2], overwrite
+		length, charset, generated, err := util.ParseGeneratePlaceholder(row[2])
+		if generated {
+			if err == nil && row[1] != "SecureString" {
+				err = fmt.Errorf("generated values must be of type SecureString")
+			}
+			if err == nil {
+				paramValue, err = util.GenerateSecret(length, charset)
+			}
 			if err != nil {
-				println(err.Error())
+				println(fmt.Sprintf("row %d: %s: %s", i+1, row[0], err.Error()))
+				continue
 			}
+			rowOverwrite = false
+		}
+		_, err = putParameter(svc, row[0], row[1], paramValue, rowOverwrite)
+		if aerr, ok := err.(awserr.Error); ok && generated && aerr.Code() == ssm.ErrCodeParameterAlreadyExists {
+			println(fmt.Sprintf("row %d: %s already exists, generated value skipped", i+1, row[0]))
+		} else if err != nil {
+			println(err.Error())
 		}
 	}
 }
@@ -275,16 +587,14 @@ func DownloadParametersByValue(targetvalue string, filterpath string) {
 		println(err.Error())
 	}
 
-	records := [][]string{}
 	fileName := fmt.Sprintf("searchbyvalue-%s-%s", output, time.Now().UTC().Format("20060102150405"))
 
-	for key, value := range params {
-		if strings.HasPrefix(value.Name, filterpath) {
-			records = append(records, []string{key, value.Type, value.Value})
-		} else {
+	for _, value := range params {
+		if !strings.HasPrefix(value.Name, filterpath) {
 			delete(params, value.Name)
 		}
 	}
+	records := params.Records()
 	if output != "" {
 		file, err := os.Create(getFilePath(fileName, "csv"))
 		if err != nil {
@@ -305,30 +615,30 @@ func DownloadParametersByValue(targetvalue string, filterpath string) {
 }
 
 // ExportParameters download all parameters linked to a project.
+// With a label the project parameters are read at their labeled versions, the common ones at their current versions.
 func ExportParameters(env string, domain string, project string) {
-	params, err := GetParametersByPath("/" + env + "/" + domain + "/" + project)
+	params, err := GetParametersByLabel(projectPath(env, domain, project), label)
 	if err != nil {
 		println(err.Error())
 	}
 
-	records := [][]string{}
-
-	for key, value := range params {
-		records = append(records, []string{key, value.Type, value.Value})
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
 	}
-
-	for _, value := range params {
-		if strings.Contains(value.Value, "/common/") {
-			common, err := GetParameterByName(value.Value)
-			if err != nil {
-				println(err.Error())
-			} else {
-				records = append(records, []string{common.Name, common.Type, common.Value})
-			}
-		}
+	commons, err := resolveCommonReferences(svc, params)
+	if err != nil {
+		println(err.Error())
 	}
+	for name, common := range commons {
+		params[name] = common
+	}
+	records := params.Records()
 
 	fileName := fmt.Sprintf("export-%s-%s-%s", project, env, time.Now().UTC().Format("20060102150405"))
+	if label != "" {
+		fileName = fmt.Sprintf("export-%s-%s-%s-%s", project, env, label, time.Now().UTC().Format("20060102150405"))
+	}
 
 	file, err := os.Create(getFilePath(fileName, "csv"))
 	if err != nil {
@@ -345,115 +655,463 @@ func ExportParameters(env string, domain string, project string) {
 	}
 }
 
-// ValidateParameters read parameters from a CSV and check for inconsistencies.
-func ValidateParameters(filename string, env string) {
-	// Open the file
-	csvfile, err := os.Open(getFilePath(filename, "csv"))
-	if err != nil {
-		println(err.Error())
+// resolveCommonReferences retrieves, once each, the common parameters referenced by the values of params.
+func resolveCommonReferences(svc ssmiface.SSMAPI, params SystemsManagerParameters) (commons SystemsManagerParameters, err error) {
+	var names []string
+	for _, value := range params {
+		if strings.Contains(value.Value, "/common/") {
+			names = append(names, value.Value)
+		}
+	}
+	if len(names) == 0 {
+		return make(SystemsManagerParameters), nil
 	}
 
-	params := make(SystemsManagerParameters)
+	commons, invalid, err := getParametersByNames(svc, names)
+	for _, name := range invalid {
+		println("can't find common parameter " + name)
+	}
+	return commons, err
+}
 
-	// Parse the file
-	r := csv.NewReader(csvfile)
+// resolveCommonValues replaces the values pointing to a common parameter with the common parameter value.
+func resolveCommonValues(svc ssmiface.SSMAPI, params SystemsManagerParameters) {
+	commons, err := resolveCommonReferences(svc, params)
+	if err != nil {
+		println(err.Error())
+	}
+	for _, value := range params {
+		if common, ok := commons[value.Value]; ok {
+			value.Value = common.Value
+		}
+	}
+}
 
-	records, err := r.ReadAll()
+// ValidateParameters read parameters from a CSV and check for inconsistencies, the report is written in the given format.
+// Unless reveal is true SecureString values are masked.
+func ValidateParameters(filename string, env string, format string, reveal bool) (results []util.ValidationResult) {
+	records, err := readCsvRecords(filename)
 	if err != nil {
 		println(err.Error())
 	}
 
+	if !CheckTargetEnvironments(records, env) {
+		log.Fatalln("validation aborted")
+	}
+
+	params := make(SystemsManagerParameters)
+
 	// Create SystemsManagerParameters from CSV data
-	for _, row := range records {
+	for i, row := range records {
+		if len(row) < 3 {
+			println(fmt.Sprintf("row %d: expected 3 columns: name,type,value", i+1))
+			continue
+		}
 		params[row[0]] = &SystemsManagerParameter{Name: row[0], Type: row[1], Value: row[2]}
 	}
 
-	// for _, param := range params {
-	// 	if !strings.HasPrefix(param.Name, "/"+env) {
-	// 		reader := bufio.NewReader(os.Stdin)
-	// 		fmt.Print("An entry with a different environment was found, are you sure do you want to continue? (Y)es/(N)o :")
-	// 		userinput, _ := reader.ReadString('\n')
-	// 		strings.ToLower(userinput)
-	// 		if text == "y" || text == "yes"{
-	// 			fmt.Println(text)
-	// 		} else if text == "n" || text == "no" {
-	// 			fmt.Println(text)
-	// 		}
-	//
-	// 	}
-	// }
+	names := params.Names()
+	existing, _, err := GetParametersByNames(names)
+	if err != nil {
+		log.Fatalln("error reading parameters:", err)
+	}
+
+	for _, name := range names {
+		param := params[name]
+		result := util.ValidationResult{Name: param.Name, Type: param.Type, NewValue: param.Value}
+		current, ok := existing[param.Name]
+		if ok {
+			result.State = util.StatePresent
+			result.OldType = current.Type
+			result.OldValue = current.Value
+		} else {
+			result.State = util.StateMissing
+		}
 
-	for _, param := range params {
 		if strings.Contains(param.Name, "/common/") {
-			commonvar, err := GetParameterByName(param.Name)
-			if err != nil {
+			if !ok {
+				result.Action = util.ActionCreate
 				commonvalues, err := GetParametersByValue(param.Value)
-				if err != nil {
-					println("MISSING -> CREATE      - " + param.Name + " WITH VALUE " + param.Value)
-				} else {
-					println("MISSING -> DUPLICATE   - " + param.Name + " WITH VALUE " + param.Value)
+				if err == nil {
+					result.Action = util.ActionDuplicate
 					for _, commonvalue := range commonvalues {
-						if strings.HasPrefix(commonvalue.Name, "/"+env+"/common") {
-							println("- " + commonvalue.Name + " with value " + commonvalue.Value)
+						if util.EnvironmentOf(commonvalue.Name, config.EnvironmentIndex()) == env && strings.Contains(commonvalue.Name, "/common/") {
+							duplicate := commonvalue.Value
+							if !reveal {
+								duplicate = util.MaskValue(commonvalue.Type, duplicate)
+							}
+							result.Duplicates = append(result.Duplicates, commonvalue.Name+" with value "+duplicate)
 						}
 					}
 				}
+			} else if current.Value == param.Value {
+				result.Action = util.ActionMaintain
 			} else {
-				if commonvar.Value == param.Value {
-					println("PRESENT -> MAINTAIN    - " + param.Name + " WITH VALUE " + param.Value)
-				} else {
-					println("PRESENT -> DESTRUCTIVE - " + param.Name + " WITH VALUE " + param.Value + " Caricare questo CSV potrebbe provocare problemi con altri progetti")
-				}
+				result.Action = util.ActionDestructive
+				result.Message = "uploading this CSV could break other projects using this common parameter"
 			}
 		} else {
-			projectvar, err := GetParameterByName(param.Name)
-			if err != nil {
-				println("MISSING -> CREATE      - " + param.Name + " WITH VALUE " + param.Value + "")
+			if !ok {
+				result.Action = util.ActionCreate
+			} else if current.Value == param.Value {
+				result.Action = util.ActionMaintain
 			} else {
-				if projectvar.Value == param.Value {
-					println("PRESENT -> MAINTAIN    - " + param.Name + " WITH VALUE " + param.Value)
-				} else {
-					println("PRESENT -> OVERWRITE   - " + projectvar.Name + " WITH VALUE " + param.Value)
-				}
+				result.Action = util.ActionOverwrite
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := util.WriteValidationReport(os.Stdout, format, results, reveal); err != nil {
+		log.Fatalln("error writing report:", err)
+	}
+	return results
+}
+
+// ValidateParametersWithSchema checks parameters from a CSV file or a live path against a schema file and prints every violation.
+func ValidateParametersWithSchema(schemafile string, filename string, path string) (violations []util.Violation) {
+	schemadata, err := ioutil.ReadFile(schemafile)
+	if err != nil {
+		log.Fatalln("error reading schema:", err)
+	}
+	schema, err := util.NewSchema(schemadata)
+	if err != nil {
+		log.Fatalln("error parsing schema:", err)
+	}
+
+	var records [][]string
+	if filename != "" {
+		records, err = readCsvRecords(filename)
+		if err != nil {
+			log.Fatalln("error reading csv:", err)
+		}
+	} else {
+		params, err := GetParametersByPath(path)
+		if err != nil {
+			log.Fatalln("error reading parameters:", err)
+		}
+		records = params.Records()
+	}
+
+	violations = schema.Validate(records)
+	for _, violation := range violations {
+		println("INVALID - " + violation.String())
+	}
+	if len(violations) == 0 {
+		println(fmt.Sprintf("VALID   - %d parameters checked", len(records)))
+	}
+	return violations
+}
+
+// LintParameters checks a CSV file for problems that would make an upload fail, without contacting AWS.
+func LintParameters(filename string, tier string) (violations []util.Violation) {
+	records, err := readCsvRecords(filename)
+	if err != nil {
+		log.Fatalln("error reading csv:", err)
+	}
+
+	violations = util.LintRecords(records, tier, config.EnvironmentIndex())
+	for _, violation := range violations {
+		println("LINT  - " + violation.String())
+	}
+	if len(violations) == 0 {
+		println(fmt.Sprintf("CLEAN - %d parameters checked", len(records)))
+	}
+	return violations
+}
+
+// AuditParameters looks for secrets stored in plaintext, weak secrets, secrets shared between environments and
+// SecureStrings of the cmkEnvs environments encrypted with the AWS managed key, in the parameters under path
+// or, when filename is set, in a local CSV before it is uploaded.
+func AuditParameters(path string, filename string, cmkEnvs []string) (violations []util.Violation) {
+	var checked int
+	if filename != "" {
+		records, err := readCsvRecords(filename)
+		if err != nil {
+			log.Fatalln("error reading csv:", err)
+		}
+		violations = util.AuditRecords(records, config.EnvironmentIndex())
+		checked = len(records)
+	} else {
+		svc, err := NewSSMClient()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		list, err := describeParameters(svc, path)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		values, err := getParametersByPath(svc, path)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		var params []util.AuditParameter
+		for _, metadata := range list {
+			param := util.AuditParameter{Name: metadata.Name, Type: metadata.Type, KeyID: metadata.KeyID}
+			if value, ok := values[metadata.Name]; ok {
+				param.Value = value.Value
 			}
+			params = append(params, param)
 		}
+		violations = util.AuditParameters(params, cmkEnvs, config.EnvironmentIndex())
+		checked = len(params)
+	}
+
+	for _, violation := range violations {
+		println("AUDIT - " + violation.String())
+	}
+	if len(violations) == 0 {
+		println(fmt.Sprintf("CLEAN - %d parameters checked", checked))
+	}
+	return violations
+}
+
+// ResolveParameters merges the inheritance layers of a project and prints the effective parameters with the layer each value came from.
+func ResolveParameters(env string, domain string, project string, format string) {
+	paths := config.LayerPaths(env, domain, project)
+	if layers != "" {
+		paths = nil
+		for _, layer := range util.SplitList(layers) {
+			paths = append(paths, util.ExpandPathTemplate(layer, env, domain, project))
+		}
+	}
+
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var merged []util.Layer
+	for _, layerPath := range paths {
+		params, err := getParametersByPath(svc, layerPath)
+		if err != nil {
+			log.Fatalln("error reading "+layerPath+":", err)
+		}
+		if recursive {
+			resolveCommonValues(svc, params)
+		}
+		merged = append(merged, util.Layer{Path: layerPath, Records: params.Records()})
+	}
+
+	err = util.WriteResolvedParameters(os.Stdout, format, util.MergeLayers(merged), reveal)
+	if err != nil {
+		log.Fatalln("error writing parameters:", err)
+	}
+}
+
+// MatrixParameters compares the parameters of a project across environments, each read from the account mapped to it in the configuration.
+func MatrixParameters(envNames []string, domain string, project string, format string) {
+	var layers []util.Layer
+	for _, envName := range envNames {
+		target := currentTarget()
+		if named, ok := config.Environments[envName]; ok {
+			if named.Profile != "" {
+				target.Profile = named.Profile
+			}
+			if named.Region != "" {
+				target.Region = named.Region
+			}
+		}
+		svc, err := newSSMClientFor(target)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		envPath := projectPath(envName, domain, project)
+		params, err := getParametersByPath(svc, envPath)
+		if err != nil {
+			log.Fatalln("error reading "+envPath+":", err)
+		}
+		layers = append(layers, util.Layer{Path: envPath, Records: params.Records()})
+	}
+
+	w := os.Stdout
+	if output != "" {
+		extension := format
+		if extension == "text" {
+			extension = "txt"
+		}
+		file, err := os.Create(getFilePath(output, extension))
+		if err != nil {
+			log.Fatalln("error creating output:", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	err := util.WriteMatrix(w, format, util.NewMatrix(envNames, layers), reveal)
+	if err != nil {
+		log.Fatalln("error writing matrix:", err)
+	}
+}
+
+// FormatCsv rewrites a CSV file sorted by name, without duplicates and with normalized quoting.
+func FormatCsv(filename string) (violations []util.Violation) {
+	records, err := readCsvRecords(filename)
+	if err != nil {
+		log.Fatalln("error reading csv:", err)
+	}
+
+	records, violations = util.CanonicalizeRecords(records)
+	if len(violations) > 0 {
+		for _, violation := range violations {
+			println("FMT - " + violation.String())
+		}
+		return violations
+	}
+
+	file, err := os.Create(getFilePath(filename, "csv"))
+	if err != nil {
+		log.Fatalln("error writing csv:", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+
+	w.WriteAll(records) // calls Flush internally
+
+	if err := w.Error(); err != nil {
+		log.Fatalln("error writing csv:", err)
 	}
+	return nil
 }
 
-// InitializeParameters read a Json config file and extract blank parameters and create a CSV file for pargolo upload.
-func InitializeParameters(filename string, env string, domain string, project string) {
+// InitializeParameters read a JSON, YAML, TOML, .properties or .env config file and extract blank parameters and create a CSV file for pargolo upload.
+// With withDefaults non-empty values are carried over, keys matching the secure rules become SecureString.
+func InitializeParameters(filename string, env string, domain string, project string, outputfile string, rulesfile string, withDefaults bool, force bool) {
 
-	// read data from file
-	jsondatafromfile, err := ioutil.ReadFile(getFilePath(filename, "json"))
+	// read data from file, the format is chosen by extension and defaults to json
+	if !util.IsSupportedConfigFile(filename) {
+		filename = getFilePath(filename, "json")
+	}
+	datafromfile, err := ioutil.ReadFile(filename)
 	if err != nil {
-		fmt.Println(err)
+		log.Fatalln("error reading config:", err)
 	}
 
-	// Create csv structure from json data
-	actualCsv, err := util.NewJSONToCsvConverter().Convert(jsondatafromfile)
+	rules := util.NewSecureKeyRules(util.DefaultSecureKeyPatterns)
+	if rulesfile != "" {
+		rulesdata, err := ioutil.ReadFile(rulesfile)
+		if err != nil {
+			log.Fatalln("error reading secure rules:", err)
+		}
+		rules = util.ParseSecureKeyRules(rulesdata)
+	}
+
+	// Create csv structure from config data
+	options := []util.ConverterOption{util.WithNaming(naming), util.WithSeparator(separator)}
+	if withDefaults {
+		options = append(options, util.WithScalars())
+	}
+	if ignoreKeysFile != "" {
+		ignoredata, err := ioutil.ReadFile(ignoreKeysFile)
+		if err != nil {
+			log.Fatalln("error reading keys to ignore:", err)
+		}
+		options = append(options, util.WithKeysToIgnore(util.ReadPatterns(ignoredata)))
+	} else {
+		options = append(options, util.WithKeysToIgnore(util.SplitList(ignoreKeys)))
+	}
+	converter, err := util.NewConverterForFile(filename, options...)
 	if err != nil {
-		fmt.Println(err)
+		log.Fatalln(err)
+	}
+	actualCsv, err := converter.ConvertWithValues(datafromfile)
+	if err != nil {
+		log.Fatalln("error converting config:", err)
 	}
 
 	//create output csvfile
-	csvdatafile, err := os.Create("./data.csv")
+	if outputfile == "" {
+		outputfile = "data"
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	csvdatafile, err := os.OpenFile(getFilePath(outputfile, "csv"), flags, 0644)
+	if os.IsExist(err) {
+		log.Fatalln(getFilePath(outputfile, "csv") + " already exists, use -force to overwrite it")
+	}
 	if err != nil {
-		fmt.Println(err)
+		log.Fatalln("error creating csv:", err)
 	}
 	defer csvdatafile.Close()
 
 	writer := csv.NewWriter(csvdatafile)
 
-	for _, key := range actualCsv {
+	sort.Slice(actualCsv, func(i, j int) bool { return actualCsv[i].Key < actualCsv[j].Key })
+	for _, row := range actualCsv {
 		var record []string
-		record = append(record, "/"+env+"/"+domain+"/"+project+"/"+key)
-		record = append(record, "String")
-		record = append(record, "")
+		record = append(record, projectPath(env, domain, project)+"/"+row.Key)
+		record = append(record, rules.TypeOf(row.Key))
+		record = append(record, row.Value)
 		writer.Write(record)
 	}
 
 	// remember to flush!
 	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Fatalln("error writing csv:", err)
+	}
+}
+
+// CheckTargetEnvironments looks for CSV rows targeting an environment different from env or a protected environment,
+// and returns true only if the user allowed them to be processed.
+func CheckTargetEnvironments(records [][]string, env string) bool {
+	protected := make(map[string]bool)
+	for _, name := range util.SplitList(protectedEnvs) {
+		protected[name] = true
+	}
+
+	targets := util.TargetEnvironments(records, config.EnvironmentIndex())
+	for _, target := range util.SortedKeys(targets) {
+		rows := targets[target]
+		if env != "" && target != env {
+			println(fmt.Sprintf("CROSS-ENV - %d rows target %q instead of %q, first at row %d", len(rows), target, env, rows[0]))
+			if !allowCrossEnv && !confirm("Are you sure you want to continue? (Y)es/(N)o: ") {
+				return false
+			}
+		}
+		if protected[target] && confirmEnv != target {
+			println(fmt.Sprintf("PROTECTED - %d rows target the protected environment %q", len(rows), target))
+			if !confirmTyped(fmt.Sprintf("Type %q to continue: ", target), target) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func confirm(question string) bool {
+	answer := strings.ToLower(prompt(question))
+	return answer == "y" || answer == "yes"
+}
+
+func confirmTyped(question string, expected string) bool {
+	return prompt(question) == expected
+}
+
+// stdin is shared by every prompt, so that answers piped together are not lost in the buffer of a previous reader
+var stdin = bufio.NewReader(os.Stdin)
+
+// prompt asks a question on the standard error, keeping the standard output for reports
+func prompt(question string) string {
+	fmt.Fprint(os.Stderr, question)
+	answer, _ := stdin.ReadString('\n')
+	return strings.TrimSpace(answer)
+}
+
+func readCsvRecords(filename string) ([][]string, error) {
+	csvfile, err := os.Open(getFilePath(filename, "csv"))
+	if err != nil {
+		return nil, err
+	}
+	defer csvfile.Close()
+
+	r := csv.NewReader(csvfile)
+	r.FieldsPerRecord = -1
+
+	return r.ReadAll()
 }
 
 func getFilePath(filename string, extension string) string {
@@ -486,64 +1144,302 @@ func main() {
 		fmt.Printf("\n--- initialize ---\n")
 		initialize.PrintDefaults()
 
+		fmt.Printf("\n--- lint ---\n")
+		lint.PrintDefaults()
+
+		fmt.Printf("\n--- fmt ---\n")
+		fmtcsv.PrintDefaults()
+
+		fmt.Printf("\n--- resolve ---\n")
+		resolve.PrintDefaults()
+
+		fmt.Printf("\n--- matrix ---\n")
+		matrix.PrintDefaults()
+
+		fmt.Printf("\n--- get ---\n")
+		get.PrintDefaults()
+
+		fmt.Printf("\n--- set ---\n")
+		set.PrintDefaults()
+
+		fmt.Printf("\n--- edit ---\n")
+		edit.PrintDefaults()
+
+		fmt.Printf("\n--- browse ---\n")
+		browse.PrintDefaults()
+
+		fmt.Printf("\n--- ls ---\n")
+		ls.PrintDefaults()
+
+		fmt.Printf("\n--- stale ---\n")
+		stale.PrintDefaults()
+
+		fmt.Printf("\n--- scan ---\n")
+		scan.PrintDefaults()
+
+		fmt.Printf("\n--- audit ---\n")
+		audit.PrintDefaults()
+
+		fmt.Printf("\n--- generate ---\n")
+		generate.PrintDefaults()
+
+		fmt.Printf("\n--- rotate ---\n")
+		rotate.PrintDefaults()
+
+		fmt.Printf("\n--- label ---\n")
+		labelcmd.PrintDefaults()
+
+		fmt.Printf("\n--- delete ---\n")
+		deletecmd.PrintDefaults()
+
 		os.Exit(0)
 	}
 
 	switch os.Args[1] {
 
 	case "searchbypath":
-		searchbypath.Parse(os.Args[2:])
+		parseFlags(searchbypath)
 		if path == "" {
 			searchbypath.PrintDefaults()
 			os.Exit(1)
 		}
+		checkLabelFlag("label", label)
+
+		if tree && (output != "" || profiles != "" || regions != "" || group != "") {
+			searchbypath.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if profiles != "" || regions != "" || group != "" {
+			FanOutParametersByPath(fanOutTargets(), path, recursive)
+			break
+		}
 
 		DownloadParametersByPath(path, recursive)
 
 	case "upload":
-		upload.Parse(os.Args[2:])
+		parseFlags(upload)
 		if input == "" {
 			upload.PrintDefaults()
 			os.Exit(1)
 		}
 
-		UploadParametersFromCsv(input, overwrite)
+		UploadParametersFromCsv(input, env, overwrite)
 
 	case "searchbyvalue":
-		searchbyvalue.Parse(os.Args[2:])
+		parseFlags(searchbyvalue)
 		if value == "" {
 			searchbyvalue.PrintDefaults()
 			os.Exit(1)
 		}
 
+		if profiles != "" || regions != "" || group != "" {
+			FanOutParametersByValue(fanOutTargets(), value, filter)
+			break
+		}
+
 		DownloadParametersByValue(value, filter)
 
 	case "export":
-		export.Parse(os.Args[2:])
+		parseFlags(export)
 		if env == "" || domain == "" || project == "" {
 			export.PrintDefaults()
 			os.Exit(1)
 		}
+		checkLabelFlag("label", label)
 
 		ExportParameters(env, domain, project)
 
 	case "validate":
-		validate.Parse(os.Args[2:])
+		parseFlags(validate)
+		if schema != "" {
+			if (input == "" && path == "") || flagPassed(validate, "format") {
+				validate.PrintDefaults()
+				os.Exit(1)
+			}
+			if len(ValidateParametersWithSchema(schema, input, path)) > 0 {
+				os.Exit(1)
+			}
+			break
+		}
 		if input == "" || env == "" {
 			validate.PrintDefaults()
 			os.Exit(1)
 		}
 
-		ValidateParameters(input, env)
+		if !util.ReportFormats[format] {
+			validate.PrintDefaults()
+			os.Exit(1)
+		}
+
+		for _, result := range ValidateParameters(input, env, format, reveal) {
+			if result.Blocking() {
+				os.Exit(exitCodeBlocking)
+			}
+		}
 
 	case "initialize":
-		initialize.Parse(os.Args[2:])
-		if input == "" || env == "" || domain == "" || project == "" {
+		parseFlags(initialize)
+		if input == "" || env == "" || domain == "" || project == "" || !util.NamingStrategies[naming] {
 			initialize.PrintDefaults()
 			os.Exit(1)
 		}
 
-		InitializeParameters(input, env, domain, project)
+		InitializeParameters(input, env, domain, project, output, secureRules, withDefaults, force)
+
+	case "lint":
+		parseFlags(lint)
+		if input == "" {
+			lint.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if len(LintParameters(input, tier)) > 0 {
+			os.Exit(1)
+		}
+
+	case "fmt":
+		parseFlags(fmtcsv)
+		if input == "" {
+			fmtcsv.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if len(FormatCsv(input)) > 0 {
+			os.Exit(1)
+		}
+
+	case "resolve":
+		parseFlags(resolve)
+		if env == "" || domain == "" || project == "" || !util.ResolveFormats[format] {
+			resolve.PrintDefaults()
+			os.Exit(1)
+		}
+
+		ResolveParameters(env, domain, project, format)
+
+	case "matrix":
+		parseFlags(matrix)
+		if envs == "" || domain == "" || project == "" || !util.MatrixFormats[format] {
+			matrix.PrintDefaults()
+			os.Exit(1)
+		}
+
+		MatrixParameters(util.SplitList(envs), domain, project, format)
+
+	case "get":
+		parseFlags(get)
+		if name == "" {
+			get.PrintDefaults()
+			os.Exit(1)
+		}
+
+		GetSingleParameter(name, raw)
+
+	case "set":
+		parseFlags(set)
+		if name == "" || !util.ParameterTypes[paramType] {
+			set.PrintDefaults()
+			os.Exit(1)
+		}
+
+		SetSingleParameter(name, paramType, overwrite, ifVersion)
+
+	case "edit":
+		parseFlags(edit)
+		if path == "" || !util.DocumentFormats[fileFormat] {
+			edit.PrintDefaults()
+			os.Exit(1)
+		}
+
+		EditParameters(path, fileFormat)
+
+	case "browse":
+		parseFlags(browse)
+		if path == "" {
+			path = "/"
+		}
+
+		BrowseParameters(path)
+
+	case "ls":
+		parseFlags(ls)
+		if path == "" || !util.ListFormats[format] || util.CheckColumns(append(util.SplitList(columns), sortBy)) != nil {
+			ls.PrintDefaults()
+			os.Exit(1)
+		}
+
+		ListParameters(path, util.SplitList(columns), sortBy, reverse, format)
+
+	case "stale":
+		parseFlags(stale)
+		age, err := util.ParseAge(olderThan)
+		if path == "" || err != nil {
+			stale.PrintDefaults()
+			os.Exit(1)
+		}
+
+		StaleParameters(path, age, output)
+
+	case "scan":
+		parseFlags(scan)
+		if dir == "" || path == "" || !util.ListFormats[format] {
+			scan.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if len(ScanReferences(dir, path, format)) > 0 {
+			os.Exit(1)
+		}
+
+	case "audit":
+		parseFlags(audit)
+		if path == "" && input == "" {
+			audit.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if len(AuditParameters(path, input, util.SplitList(requireCMK))) > 0 {
+			os.Exit(1)
+		}
+
+	case "generate":
+		parseFlags(generate)
+		// a misspelled or positional name would print the secret instead of storing it
+		if generate.NArg() > 0 || length <= 0 || (name == "" && overwrite) {
+			generate.PrintDefaults()
+			os.Exit(1)
+		}
+
+		GenerateParameter(name, length, charset, overwrite)
+
+	case "rotate":
+		parseFlags(rotate)
+		if path == "" {
+			rotate.PrintDefaults()
+			os.Exit(1)
+		}
+		checkLabelFlag("previous-label", previousLabel)
+
+		RotateParameters(path, util.SplitList(match), length, charset, previousLabel, hook, force)
+
+	case "label":
+		parseFlags(labelcmd)
+		if path == "" || util.CheckLabel(label) != nil {
+			labelcmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		LabelParameters(path, label)
+
+	case "delete":
+		parseFlags(deletecmd)
+		if input == "" {
+			deletecmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		DeleteParametersFromCsv(input, env, force)
 
 	default:
 		flag.PrintDefaults()
@@ -552,34 +1448,177 @@ func main() {
 	}
 }
 
+// flagPassed returns true if the flag was given on the command line
+func flagPassed(flagset *flag.FlagSet, name string) bool {
+	passed := false
+	flagset.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
+// checkLabelFlag exits if an optional label flag has a value Parameter Store would reject
+func checkLabelFlag(name string, label string) {
+	if label != "" {
+		if err := util.CheckLabel(label); err != nil {
+			log.Fatalln("invalid -"+name+":", err)
+		}
+	}
+}
+
+func defaultProtectedEnvs() string {
+	if envs, ok := os.LookupEnv("PARGOLO_PROTECTED_ENVS"); ok {
+		return envs
+	}
+	return "prod"
+}
+
 func init() {
 	searchbypath = flag.NewFlagSet("SearchByPath", flag.ExitOnError)
-	searchbypath.StringVar(&profile, "profile", "", "(optional) AWS profile")
+	addCredentialFlags(searchbypath)
 	searchbypath.StringVar(&path, "path", "", "(required) prefix path to download")
 	searchbypath.StringVar(&output, "output", "", "(optional) Output CSV file")
 	searchbypath.BoolVar(&recursive, "recursive", false, "(optional) Select if pargolo should recursively resolve parameters value")
+	searchbypath.BoolVar(&tree, "tree", false, "(optional) Print the parameters as a tree with the number of parameters of every subtree")
+	searchbypath.IntVar(&depth, "depth", 0, "(optional) Number of levels shown by -tree, 0 shows all")
+	searchbypath.BoolVar(&reveal, "reveal", false, "(optional) Show SecureString values in the -tree previews instead of masking them")
+	searchbypath.StringVar(&label, "label", "", "(optional) Read the versions with this label instead of the current ones")
+	addFanOutFlags(searchbypath)
 	searchbyvalue = flag.NewFlagSet("SearchByValue", flag.ExitOnError)
-	searchbyvalue.StringVar(&profile, "profile", "", "(optional) AWS profile")
+	addCredentialFlags(searchbyvalue)
 	searchbyvalue.StringVar(&value, "value", "", "(required) The Value to search")
 	searchbyvalue.StringVar(&filter, "filter", "", "(optional) Filters the results by path")
 	searchbyvalue.StringVar(&output, "output", "", "(optional) Output CSV file")
+	addFanOutFlags(searchbyvalue)
 	upload = flag.NewFlagSet("Upload", flag.ExitOnError)
-	upload.StringVar(&profile, "profile", "", "(optional) AWS profile")
+	addCredentialFlags(upload)
 	upload.StringVar(&input, "input", "", "(required) Input CSV file")
 	upload.BoolVar(&overwrite, "overwrite", false, "(optional) Overwrite the value if the key already exists")
+	upload.StringVar(&env, "env", "", "(optional) The target environment, rows targeting a different one must be confirmed")
+	upload.BoolVar(&allowCrossEnv, "allow-cross-env", false, "(optional) Process rows targeting a different environment without confirmation")
+	upload.StringVar(&protectedEnvs, "protected-envs", defaultProtectedEnvs(), "(optional) Comma separated environments that always require confirmation")
+	upload.StringVar(&confirmEnv, "confirm", "", "(optional) Name of the protected environment to process without interactive confirmation")
 	export = flag.NewFlagSet("Export", flag.ExitOnError)
-	export.StringVar(&profile, "profile", "", "(optional) AWS profile")
+	addCredentialFlags(export)
 	export.StringVar(&env, "env", "", "(required) The source environment")
 	export.StringVar(&domain, "domain", "", "(required) The project domain")
 	export.StringVar(&project, "project", "", "(required) The project name")
+	export.StringVar(&label, "label", "", "(optional) Export the versions with this label instead of the current ones")
 	validate = flag.NewFlagSet("Validate", flag.ExitOnError)
-	validate.StringVar(&profile, "profile", "", "(optional) AWS profile")
+	addCredentialFlags(validate)
 	validate.StringVar(&input, "input", "", "(required) Input CSV file")
 	validate.StringVar(&env, "env", "", "(required) The target environment")
+	validate.BoolVar(&allowCrossEnv, "allow-cross-env", false, "(optional) Process rows targeting a different environment without confirmation")
+	validate.StringVar(&protectedEnvs, "protected-envs", defaultProtectedEnvs(), "(optional) Comma separated environments that always require confirmation")
+	validate.StringVar(&confirmEnv, "confirm", "", "(optional) Name of the protected environment to process without interactive confirmation")
+	validate.StringVar(&format, "format", "text", "(optional) Report format: text, json, junit or markdown")
+	validate.BoolVar(&reveal, "reveal", false, "(optional) Show SecureString values in the report instead of masking them")
+	validate.StringVar(&schema, "schema", "", "(optional) YAML or JSON schema file, validates the input CSV offline or the -path parameters read from the store; -format is not supported")
+	validate.StringVar(&path, "path", "", "(optional) prefix path to validate against the schema instead of an input CSV")
 	initialize = flag.NewFlagSet("Initialize", flag.ExitOnError)
-	initialize.StringVar(&profile, "profile", "", "(optional) AWS profile")
-	initialize.StringVar(&input, "input", "", "(required) Input JSON config file")
+	addCredentialFlags(initialize)
+	initialize.StringVar(&input, "input", "", "(required) Input config file: .json, .yaml, .yml, .toml, .properties or .env")
 	initialize.StringVar(&env, "env", "", "(required) The source environment")
 	initialize.StringVar(&domain, "domain", "", "(required) The project domain")
 	initialize.StringVar(&project, "project", "", "(required) The project name")
+	initialize.StringVar(&output, "output", "", "(optional) Output CSV file, defaults to data.csv")
+	initialize.BoolVar(&withDefaults, "with-defaults", false, "(optional) Use the non-empty config values as initial values")
+	initialize.StringVar(&secureRules, "secure-rules", "", "(optional) File with one key pattern per line marking SecureString parameters")
+	initialize.BoolVar(&force, "force", false, "(optional) Overwrite the output file if it already exists")
+	initialize.StringVar(&naming, "naming", util.NamingLower, "(optional) Key naming strategy: preserve, lower, kebab or snake")
+	initialize.StringVar(&separator, "separator", "/", "(optional) Separator between key segments")
+	initialize.StringVar(&ignoreKeys, "ignore-keys", strings.Join(util.DefaultKeysToIgnore, ","), "(optional) Comma separated keys to ignore, glob patterns are supported")
+	initialize.StringVar(&ignoreKeysFile, "ignore-keys-file", "", "(optional) File with one key to ignore per line, replaces -ignore-keys")
+	lint = flag.NewFlagSet("Lint", flag.ExitOnError)
+	lint.StringVar(&input, "input", "", "(required) Input CSV file")
+	lint.StringVar(&tier, "tier", "Standard", "(optional) Parameter tier used to check value sizes: Standard or Advanced")
+	fmtcsv = flag.NewFlagSet("Fmt", flag.ExitOnError)
+	fmtcsv.StringVar(&input, "input", "", "(required) CSV file to format in place")
+	resolve = flag.NewFlagSet("Resolve", flag.ExitOnError)
+	addCredentialFlags(resolve)
+	resolve.StringVar(&env, "env", "", "(required) The environment")
+	resolve.StringVar(&domain, "domain", "", "(required) The project domain")
+	resolve.StringVar(&project, "project", "", "(required) The project name")
+	resolve.StringVar(&layers, "layers", "", "(optional) Comma separated path templates of the layers, from the lowest to the highest precedence")
+	resolve.StringVar(&format, "format", "text", "(optional) Output format: text, csv, json or markdown")
+	resolve.BoolVar(&reveal, "reveal", false, "(optional) Show SecureString values instead of masking them")
+	resolve.BoolVar(&recursive, "recursive", false, "(optional) Select if pargolo should recursively resolve parameters value")
+	matrix = flag.NewFlagSet("Matrix", flag.ExitOnError)
+	addCredentialFlags(matrix)
+	matrix.StringVar(&envs, "envs", "", "(required) Comma separated environments to compare")
+	matrix.StringVar(&domain, "domain", "", "(required) The project domain")
+	matrix.StringVar(&project, "project", "", "(required) The project name")
+	matrix.StringVar(&format, "format", "text", "(optional) Output format: text, csv or html")
+	matrix.StringVar(&output, "output", "", "(optional) Output file, the extension is added from the format")
+	matrix.BoolVar(&reveal, "reveal", false, "(optional) Show SecureString values instead of masking them")
+	get = flag.NewFlagSet("Get", flag.ExitOnError)
+	addCredentialFlags(get)
+	get.StringVar(&name, "name", "", "(required) The parameter name")
+	get.BoolVar(&raw, "raw", false, "(optional) Print only the value, for scripts")
+	set = flag.NewFlagSet("Set", flag.ExitOnError)
+	addCredentialFlags(set)
+	set.StringVar(&name, "name", "", "(required) The parameter name")
+	set.StringVar(&paramType, "type", "String", "(optional) The parameter type: String, StringList or SecureString")
+	set.StringVar(&value, "value", "", "(optional) The parameter value, when both -value and -file are missing it is read from stdin")
+	set.StringVar(&valueFile, "file", "", "(optional) File containing the parameter value, e.g. a certificate PEM")
+	set.BoolVar(&overwrite, "overwrite", false, "(optional) Overwrite the value if the key already exists")
+	set.Int64Var(&ifVersion, "if-version", 0, "(optional) Refuse the write unless the current version of the parameter is this one, checked right before writing")
+	edit = flag.NewFlagSet("Edit", flag.ExitOnError)
+	addCredentialFlags(edit)
+	edit.StringVar(&path, "path", "", "(required) prefix path to edit")
+	edit.StringVar(&fileFormat, "format", "csv", "(optional) Format of the edited file: csv, yaml or json")
+	browse = flag.NewFlagSet("Browse", flag.ExitOnError)
+	addCredentialFlags(browse)
+	browse.StringVar(&path, "path", "", "(optional) prefix path to browse, defaults to /")
+	ls = flag.NewFlagSet("Ls", flag.ExitOnError)
+	addCredentialFlags(ls)
+	ls.StringVar(&path, "path", "", "(required) prefix path to list")
+	ls.StringVar(&columns, "columns", strings.Join(util.MetadataColumns, ","), "(optional) Comma separated columns to show")
+	ls.StringVar(&sortBy, "sort", "name", "(optional) Column to sort by")
+	ls.BoolVar(&reverse, "reverse", false, "(optional) Sort in descending order")
+	ls.StringVar(&format, "format", "text", "(optional) Output format: text or csv")
+	stale = flag.NewFlagSet("Stale", flag.ExitOnError)
+	addCredentialFlags(stale)
+	stale.StringVar(&path, "path", "", "(required) prefix path to check")
+	stale.StringVar(&olderThan, "older-than", "180d", "(optional) Minimum age of the last change, e.g. 180d, 4w or 72h")
+	stale.StringVar(&output, "output", "", "(optional) Output CSV file, the report is printed when missing")
+	scan = flag.NewFlagSet("Scan", flag.ExitOnError)
+	addCredentialFlags(scan)
+	scan.StringVar(&dir, "dir", "", "(required) Directory with the source and config files to scan")
+	scan.StringVar(&path, "path", "", "(required) prefix path of the parameters to look for")
+	scan.StringVar(&format, "format", "text", "(optional) Output format: text or csv")
+	audit = flag.NewFlagSet("Audit", flag.ExitOnError)
+	addCredentialFlags(audit)
+	audit.StringVar(&path, "path", "", "(required) prefix path to audit, unless -input is given")
+	audit.StringVar(&input, "input", "", "(optional) CSV file to audit before uploading it, instead of the parameter store")
+	audit.StringVar(&requireCMK, "require-cmk", defaultProtectedEnvs(), "(optional) Comma separated environments whose SecureStrings must use a customer managed key")
+	generate = flag.NewFlagSet("Generate", flag.ExitOnError)
+	addCredentialFlags(generate)
+	generate.StringVar(&name, "name", "", "(optional) The SecureString parameter to write, the value is printed when missing")
+	generate.IntVar(&length, "length", util.DefaultSecretLength, "(optional) Length of the generated value")
+	generate.StringVar(&charset, "charset", "alnum", "(optional) Characters of the generated value: alnum, alpha, digits, hex, symbols or the characters themselves")
+	generate.BoolVar(&overwrite, "overwrite", false, "(optional) Overwrite the value if the key already exists")
+	rotate = flag.NewFlagSet("Rotate", flag.ExitOnError)
+	addCredentialFlags(rotate)
+	rotate.StringVar(&path, "path", "", "(required) prefix path of the SecureString parameters to rotate")
+	rotate.StringVar(&match, "match", "", "(optional) Comma separated key patterns to rotate, as in -secure-rules, all SecureStrings when missing")
+	rotate.IntVar(&length, "length", util.DefaultSecretLength, "(optional) Length of the generated values")
+	rotate.StringVar(&charset, "charset", "alnum", "(optional) Characters of the generated values: alnum, alpha, digits, hex, symbols or the characters themselves")
+	rotate.StringVar(&previousLabel, "previous-label", "previous", "(optional) Label given to the replaced version, empty to skip labeling")
+	rotate.StringVar(&hook, "hook", "", "(optional) Shell command run after each rotation, it reads the new value from stdin")
+	rotate.BoolVar(&force, "force", false, "(optional) Rotate without confirmation")
+	labelcmd = flag.NewFlagSet("Label", flag.ExitOnError)
+	addCredentialFlags(labelcmd)
+	labelcmd.StringVar(&path, "path", "", "(required) prefix path of the parameters to label")
+	labelcmd.StringVar(&label, "label", "", "(required) Label attached to the current version of every parameter, e.g. release-42")
+	deletecmd = flag.NewFlagSet("Delete", flag.ExitOnError)
+	addCredentialFlags(deletecmd)
+	deletecmd.StringVar(&input, "input", "", "(required) Input CSV file, the parameter names are read from the first column, e.g. the output of stale")
+	deletecmd.StringVar(&env, "env", "", "(optional) The target environment, rows targeting a different one must be confirmed")
+	deletecmd.BoolVar(&allowCrossEnv, "allow-cross-env", false, "(optional) Process rows targeting a different environment without confirmation")
+	deletecmd.StringVar(&protectedEnvs, "protected-envs", defaultProtectedEnvs(), "(optional) Comma separated environments that always require confirmation")
+	deletecmd.StringVar(&confirmEnv, "confirm", "", "(optional) Name of the protected environment to process without interactive confirmation")
+	deletecmd.BoolVar(&force, "force", false, "(optional) Delete without confirmation")
 }
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/ingordigia/pargolo/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetParametersByNamesBatches(t *testing.T) {
+	var records [][]string
+	var names []string
+	for i := 0; i < 22; i++ {
+		name := fmt.Sprintf("/dev/dom/proj/key%02d", i)
+		records = append(records, []string{name, "String", fmt.Sprint(i)})
+		names = append(names, name)
+	}
+	store := newMemoryStore(records)
+	names = append(names, "/dev/dom/proj/key00", "/dev/dom/proj/missing")
+
+	params, invalid, err := getParametersByNames(store, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 22, len(params))
+	assert.Equal(t, "21", params["/dev/dom/proj/key21"].Value)
+	assert.Equal(t, int64(1), params["/dev/dom/proj/key21"].Version)
+	assert.Equal(t, []string{"/dev/dom/proj/missing"}, invalid)
+	assert.Equal(t, 3, len(store.getParametersCalls))
+	assert.Equal(t, 10, len(store.getParametersCalls[0]))
+	assert.Equal(t, 3, len(store.getParametersCalls[2]))
+}
+
+func TestPutParameterKeepsKey(t *testing.T) {
+	store := newMemoryStore([][]string{{"/prod/dom/proj/db/password", "SecureString", "old"}})
+	store.current("/prod/dom/proj/db/password").KeyID = "alias/pargolo"
+
+	version, err := putParameter(store, "/prod/dom/proj/db/password", "SecureString", "new", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(2), version)
+	assert.Equal(t, "alias/pargolo", store.current("/prod/dom/proj/db/password").KeyID)
+}
+
+func TestUploadRecordsGeneratesOnce(t *testing.T) {
+	store := newMemoryStore([][]string{{"/prod/dom/proj/api/key", "SecureString", "existing"}})
+
+	uploadRecords(store, [][]string{
+		{"/prod/dom/proj/db/password", "SecureString", "!generate:16:hex"},
+		{"/prod/dom/proj/api/key", "SecureString", "!generate"},
+		{"/prod/dom/proj/api/token", "String", "!generate"},
+		{"/prod/dom/proj/api/secret", "SecureString", "!generate:3two"},
+	}, true)
+
+	assert.Equal(t, 16, len(store.current("/prod/dom/proj/db/password").Value))
+	assert.Equal(t, "existing", store.current("/prod/dom/proj/api/key").Value)
+	assert.Equal(t, int64(1), store.current("/prod/dom/proj/api/key").Version)
+	assert.Nil(t, store.current("/prod/dom/proj/api/token"))
+	assert.Nil(t, store.current("/prod/dom/proj/api/secret"))
+}
+
+func TestGetParametersByLabelSkipsUnlabeled(t *testing.T) {
+	store := newMemoryStore([][]string{
+		{"/prod/dom/proj/webapp/host", "String", "example.com"},
+		{"/prod/dom/proj/webapp/port", "String", "8080"},
+	})
+	store.current("/prod/dom/proj/webapp/host").Labels = []string{"release"}
+
+	params, err := getParametersByLabel(store, "/prod/dom/proj", "release")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, len(params))
+	assert.Equal(t, "example.com", params["/prod/dom/proj/webapp/host"].Value)
+}
+
+func TestGetParametersByNamesMalformedName(t *testing.T) {
+	store := newMemoryStore([][]string{
+		{"/dev/dom/proj/webapp/host", "String", "example.com"},
+		{"/dev/dom/proj/webapp/port", "String", "8080"},
+	})
+
+	params, invalid, err := getParametersByNames(store, []string{"/dev/dom/proj/webapp/host", "/dev/dom/proj/my key", "/dev/dom/proj/webapp/port"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, len(params))
+	assert.Equal(t, []string{"/dev/dom/proj/my key"}, invalid)
+}
+
+func TestDeleteParametersBatches(t *testing.T) {
+	var records [][]string
+	var names []string
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("/dev/dom/proj/key%02d", i)
+		records = append(records, []string{name, "String", fmt.Sprint(i)})
+		names = append(names, name)
+	}
+	store := newMemoryStore(append(records, []string{"/dev/dom/proj/kept", "String", "kept"}))
+
+	deleted, invalid, err := deleteParameters(store, append(names, "/dev/dom/proj/missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, names, deleted)
+	assert.Equal(t, []string{"/dev/dom/proj/missing"}, invalid)
+	assert.Nil(t, store.current("/dev/dom/proj/key00"))
+	assert.Equal(t, "kept", store.current("/dev/dom/proj/kept").Value)
+}
+
+func TestRotateRunsHookAfterConcurrentChange(t *testing.T) {
+	store := newMemoryStore([][]string{{"/prod/dom/proj/db/password", "SecureString", "first"}})
+	putParameter(store, "/prod/dom/proj/db/password", "SecureString", "second", true)
+	// the metadata was read before the second version was written
+	metadata := util.ParameterMetadata{Name: "/prod/dom/proj/db/password", Type: "SecureString", Version: 1}
+	propagated := filepath.Join(t.TempDir(), "secret")
+
+	version, err := rotateParameter(store, metadata, 16, "hex", "previous", "cat > "+propagated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(3), version)
+	secret, err := ioutil.ReadFile(propagated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, store.current("/prod/dom/proj/db/password").Value, string(secret))
+}
+
+func TestLabelParametersContinuesAfterFailure(t *testing.T) {
+	store := newMemoryStore([][]string{
+		{"/prod/dom/proj/a", "String", "a"},
+		{"/prod/dom/proj/b", "String", "b"},
+		{"/prod/dom/proj/c", "String", "c"},
+	})
+	list := []util.ParameterMetadata{
+		{Name: "/prod/dom/proj/a", Version: 1},
+		{Name: "/prod/dom/proj/b", Version: 7},
+		{Name: "/prod/dom/proj/c", Version: 1},
+	}
+
+	failed := labelParameters(store, list, "release-42")
+
+	assert.Equal(t, []string{"/prod/dom/proj/b"}, failed)
+	assert.Equal(t, []string{"release-42"}, store.current("/prod/dom/proj/a").Labels)
+	assert.Equal(t, []string{"release-42"}, store.current("/prod/dom/proj/c").Labels)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// validSelector matches the parameter names, optionally followed by a version or label selector, accepted by GetParameters
+var validSelector = regexp.MustCompile(`^[A-Za-z0-9_.\-/]+(:[A-Za-z0-9_.\-]+)?$`)
+
+// memoryStore is an in-process parameter store implementing the Systems Manager calls used by pargolo
+type memoryStore struct {
+	ssmiface.SSMAPI
+	lock       sync.Mutex
+	parameters map[string][]*memoryVersion
+	// getParametersCalls records the names requested by every GetParameters call
+	getParametersCalls [][]string
+}
+
+type memoryVersion struct {
+	Type    string
+	Value   string
+	KeyID   string
+	Version int64
+	Labels  []string
+}
+
+func newMemoryStore(records [][]string) *memoryStore {
+	store := &memoryStore{parameters: make(map[string][]*memoryVersion)}
+	for _, row := range records {
+		store.PutParameter(&ssm.PutParameterInput{Name: aws.String(row[0]), Type: aws.String(row[1]), Value: aws.String(row[2])})
+	}
+	return store
+}
+
+func (s *memoryStore) current(name string) *memoryVersion {
+	versions := s.parameters[name]
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions[len(versions)-1]
+}
+
+func (s *memoryStore) sortedNames() []string {
+	var names []string
+	for name := range s.parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *memoryStore) parameter(name string, version *memoryVersion) *ssm.Parameter {
+	return &ssm.Parameter{Name: aws.String(name), Type: aws.String(version.Type), Value: aws.String(version.Value), Version: aws.Int64(version.Version)}
+}
+
+func underPath(name string, path string, recursive bool) bool {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	return strings.HasPrefix(name, prefix) && (recursive || !strings.Contains(name[len(prefix):], "/"))
+}
+
+// page returns the items of the page starting at the token, and the token of the next page
+func page(count int, token *string, maxResults *int64) (start int, end int, next *string) {
+	if token != nil {
+		start, _ = strconv.Atoi(*token)
+	}
+	end = count
+	if maxResults != nil && start+int(*maxResults) < count {
+		end = start + int(*maxResults)
+		next = aws.String(strconv.Itoa(end))
+	}
+	return start, end, next
+}
+
+func (s *memoryStore) DescribeParameters(input *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var matching []string
+	for _, name := range s.sortedNames() {
+		ok := true
+		for _, filter := range input.ParameterFilters {
+			value := aws.StringValue(filter.Values[0])
+			switch aws.StringValue(filter.Key) {
+			case "Path":
+				ok = ok && underPath(name, value, aws.StringValue(filter.Option) == "Recursive")
+			case "Name":
+				ok = ok && name == value
+			}
+		}
+		if ok {
+			matching = append(matching, name)
+		}
+	}
+
+	output := &ssm.DescribeParametersOutput{}
+	start, end, next := page(len(matching), input.NextToken, input.MaxResults)
+	for _, name := range matching[start:end] {
+		version := s.current(name)
+		output.Parameters = append(output.Parameters, &ssm.ParameterMetadata{
+			Name: aws.String(name), Type: aws.String(version.Type), Version: aws.Int64(version.Version), KeyId: aws.String(version.KeyID), Tier: aws.String("Standard"),
+		})
+	}
+	output.NextToken = next
+	return output, nil
+}
+
+func (s *memoryStore) GetParametersByPath(input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var matching []string
+	for _, name := range s.sortedNames() {
+		if underPath(name, aws.StringValue(input.Path), aws.BoolValue(input.Recursive)) {
+			matching = append(matching, name)
+		}
+	}
+
+	output := &ssm.GetParametersByPathOutput{}
+	start, end, next := page(len(matching), input.NextToken, input.MaxResults)
+	for _, name := range matching[start:end] {
+		output.Parameters = append(output.Parameters, s.parameter(name, s.current(name)))
+	}
+	output.NextToken = next
+	return output, nil
+}
+
+func (s *memoryStore) GetParameters(input *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	names := aws.StringValueSlice(input.Names)
+	s.getParametersCalls = append(s.getParametersCalls, names)
+	if len(names) > maxNamesPerRequest {
+		return nil, awserr.New("ValidationException", fmt.Sprintf("%d names, at most %d are accepted", len(names), maxNamesPerRequest), nil)
+	}
+	for _, selector := range names {
+		if !validSelector.MatchString(selector) {
+			return nil, awserr.New("ValidationException", "invalid parameter name "+selector, nil)
+		}
+	}
+
+	output := &ssm.GetParametersOutput{}
+	for _, selector := range names {
+		name, label := selector, ""
+		if i := strings.LastIndex(selector, ":"); i > 0 {
+			name, label = selector[:i], selector[i+1:]
+		}
+		var found *memoryVersion
+		for _, version := range s.parameters[name] {
+			for _, l := range version.Labels {
+				if l == label {
+					found = version
+				}
+			}
+		}
+		if label == "" {
+			found = s.current(name)
+		}
+		if found == nil {
+			output.InvalidParameters = append(output.InvalidParameters, aws.String(selector))
+			continue
+		}
+		output.Parameters = append(output.Parameters, s.parameter(name, found))
+	}
+	return output, nil
+}
+
+func (s *memoryStore) PutParameter(input *ssm.PutParameterInput) (*ssm.PutParameterOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	name := aws.StringValue(input.Name)
+	current := s.current(name)
+	if current != nil && !aws.BoolValue(input.Overwrite) {
+		return nil, awserr.New(ssm.ErrCodeParameterAlreadyExists, "The parameter already exists.", nil)
+	}
+	version := &memoryVersion{Type: aws.StringValue(input.Type), Value: aws.StringValue(input.Value), KeyID: aws.StringValue(input.KeyId), Version: 1}
+	if version.Type == "SecureString" && version.KeyID == "" {
+		version.KeyID = "alias/aws/ssm"
+	}
+	if current != nil {
+		version.Version = current.Version + 1
+	}
+	s.parameters[name] = append(s.parameters[name], version)
+	return &ssm.PutParameterOutput{Version: aws.Int64(version.Version)}, nil
+}
+
+func (s *memoryStore) DeleteParameter(input *ssm.DeleteParameterInput) (*ssm.DeleteParameterOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	name := aws.StringValue(input.Name)
+	if _, ok := s.parameters[name]; !ok {
+		return nil, awserr.New(ssm.ErrCodeParameterNotFound, "", nil)
+	}
+	delete(s.parameters, name)
+	return &ssm.DeleteParameterOutput{}, nil
+}
+
+func (s *memoryStore) DeleteParameters(input *ssm.DeleteParametersInput) (*ssm.DeleteParametersOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	output := &ssm.DeleteParametersOutput{}
+	for _, name := range aws.StringValueSlice(input.Names) {
+		if _, ok := s.parameters[name]; !ok {
+			output.InvalidParameters = append(output.InvalidParameters, aws.String(name))
+			continue
+		}
+		delete(s.parameters, name)
+		output.DeletedParameters = append(output.DeletedParameters, aws.String(name))
+	}
+	return output, nil
+}
+
+func (s *memoryStore) LabelParameterVersion(input *ssm.LabelParameterVersionInput) (*ssm.LabelParameterVersionOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	labels := aws.StringValueSlice(input.Labels)
+	found := false
+	for _, version := range s.parameters[aws.StringValue(input.Name)] {
+		found = found || version.Version == aws.Int64Value(input.ParameterVersion)
+	}
+	if !found {
+		return nil, awserr.New(ssm.ErrCodeParameterVersionNotFound, "", nil)
+	}
+	for _, version := range s.parameters[aws.StringValue(input.Name)] {
+		var kept []string
+		for _, l := range version.Labels {
+			if !stringInSlice(l, labels) {
+				kept = append(kept, l)
+			}
+		}
+		version.Labels = kept
+		if version.Version == aws.Int64Value(input.ParameterVersion) {
+			version.Labels = append(version.Labels, labels...)
+		}
+	}
+	return &ssm.LabelParameterVersionOutput{}, nil
+}
+
+func stringInSlice(value string, list []string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
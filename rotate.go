@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/ingordigia/pargolo/util"
+)
+
+var length int
+var charset, match, previousLabel, hook string
+
+// GenerateParameter writes a random SecureString parameter without printing its value.
+// Without a parameter name nothing is written and the generated value is printed on the standard output.
+func GenerateParameter(paramName string, length int, charset string, overwrite bool) {
+	secret, err := util.GenerateSecret(length, charset)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if paramName == "" {
+		fmt.Println(secret)
+		return
+	}
+
+	version, err := PutParameter(paramName, "SecureString", secret, overwrite)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Printf("%s set to version %d\n", paramName, version)
+}
+
+// RotateParameters regenerates the SecureString parameters under path matching the patterns, all of them if there are none.
+// The version being replaced gets the previous label and the hook, if any, is run after every rotation.
+func RotateParameters(path string, patterns []string, length int, charset string, previousLabel string, hook string, force bool) {
+	svc, err := NewSSMClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	list, err := describeParameters(svc, path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	rules := util.NewSecureKeyRules(patterns)
+	var selected []util.ParameterMetadata
+	for _, metadata := range list {
+		if metadata.Type == "SecureString" && (len(patterns) == 0 || rules.IsSecure(metadata.Name)) {
+			selected = append(selected, metadata)
+		}
+	}
+	if len(selected) == 0 {
+		println("no SecureString parameters to rotate under " + path)
+		return
+	}
+	util.SortMetadata(selected, "name", false)
+	for _, metadata := range selected {
+		println("ROTATE  - " + metadata.Name)
+	}
+	if !force && !confirm(fmt.Sprintf("Rotate %d parameters? [y/N] ", len(selected))) {
+		log.Fatalln("rotation aborted")
+	}
+
+	failed := false
+	for _, metadata := range selected {
+		version, err := rotateParameter(svc, metadata, length, charset, previousLabel, hook)
+		if err != nil {
+			println("FAILED  - " + metadata.Name + ": " + err.Error())
+			failed = true
+			continue
+		}
+		println(fmt.Sprintf("ROTATED - %s version %d", metadata.Name, version))
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func rotateParameter(svc ssmiface.SSMAPI, metadata util.ParameterMetadata, length int, charset string, previousLabel string, hook string) (version int64, err error) {
+	secret, err := util.GenerateSecret(length, charset)
+	if err != nil {
+		return 0, err
+	}
+
+	if previousLabel != "" {
+		_, err = svc.LabelParameterVersion(&ssm.LabelParameterVersionInput{
+			Name:             aws.String(metadata.Name),
+			ParameterVersion: aws.Int64(metadata.Version),
+			Labels:           aws.StringSlice([]string{previousLabel}),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	input := &ssm.PutParameterInput{
+		Name:      aws.String(metadata.Name),
+		Type:      aws.String(metadata.Type),
+		Value:     aws.String(secret),
+		Overwrite: aws.Bool(true),
+	}
+	if metadata.KeyID != "" {
+		input.KeyId = aws.String(metadata.KeyID)
+	}
+	output, err := svc.PutParameter(input)
+	if err != nil {
+		return 0, err
+	}
+	version = aws.Int64Value(output.Version)
+	if version != metadata.Version+1 {
+		// the new secret is stored anyway, so the hook still runs to propagate it
+		message := fmt.Sprintf("WARNING - %s was modified by someone else during the rotation, the new secret is version %d", metadata.Name, version)
+		if previousLabel != "" {
+			message += fmt.Sprintf(" and %s is on version %d instead of the replaced one", previousLabel, metadata.Version)
+		}
+		println(message)
+	}
+
+	if hook != "" {
+		if err := runHook(hook, metadata.Name, version, secret); err != nil {
+			return version, fmt.Errorf("hook failed, the replaced value is labeled %s: %v", previousLabel, err)
+		}
+	}
+	return version, nil
+}
+
+// runHook runs the hook with the shell, the new secret is passed on the standard input
+// and the parameter name and version in the PARGOLO_PARAMETER_NAME and PARGOLO_PARAMETER_VERSION variables
+func runHook(hook string, paramName string, version int64, secret string) error {
+	cmd := exec.Command("sh", "-c", hook)
+	cmd.Env = append(os.Environ(),
+		"PARGOLO_PARAMETER_NAME="+paramName,
+		"PARGOLO_PARAMETER_VERSION="+strconv.FormatInt(version, 10),
+	)
+	cmd.Stdin = strings.NewReader(secret)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
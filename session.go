@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+var region, roleArn, externalID, mfaSerial string
+var roleDuration time.Duration
+
+// sessions caches the AWS sessions for the whole execution, one per target and role.
+// credentialSessions holds one session per profile and role whose temporary credentials are shared by the sessions
+// of every region, so that MFA tokens are requested only once per profile and role.
+var sessions = make(map[string]*session.Session)
+var credentialSessions = make(map[string]*session.Session)
+var sessionsLock sync.Mutex
+
+// defaultRegion is used when no region is selected
+const defaultRegion = "eu-west-1" // EU (Ireland)
+
+// awsTarget identifies the account, through its profile, and the region to operate on
+type awsTarget struct {
+	Profile string
+	Region  string
+}
+
+func (t awsTarget) String() string {
+	name := t.Profile
+	if name == "" {
+		name = "default"
+	}
+	return name + "/" + t.Region
+}
+
+// currentTarget returns the target selected by the -profile and -region flags
+func currentTarget() awsTarget {
+	return awsTarget{Profile: profile, Region: region}
+}
+
+// NewSSMClient returns a Systems Manager client for the selected profile and region
+func NewSSMClient() (ssmiface.SSMAPI, error) {
+	return newSSMClientFor(currentTarget())
+}
+
+func newSSMClientFor(target awsTarget) (ssmiface.SSMAPI, error) {
+	sess, err := createSessionFor(target)
+	if err != nil {
+		return nil, err
+	}
+	return ssm.New(sess), nil
+}
+
+// CreateSession returns an AWS session for the selected profile, region and role.
+// Profiles are read from the shared config, so role_arn, source_profile, mfa_serial, SSO and credential_process are honored.
+func CreateSession() (sess *session.Session, err error) {
+	return createSessionFor(currentTarget())
+}
+
+func createSessionFor(target awsTarget) (sess *session.Session, err error) {
+	awsRegion := target.Region
+	if awsRegion == "" {
+		awsRegion = defaultRegion
+	}
+	key := target.Profile + "|" + roleArn + "|" + externalID + "|" + mfaSerial
+
+	sessionsLock.Lock()
+	defer sessionsLock.Unlock()
+	if sess, ok := sessions[key+"|"+awsRegion]; ok {
+		return sess, nil
+	}
+
+	base, ok := credentialSessions[key]
+	if !ok {
+		base, err = newCredentialSession(target.Profile, awsRegion, key)
+		if err != nil {
+			return nil, err
+		}
+		credentialSessions[key] = base
+	}
+
+	// the copy shares the credentials of the base session, only the region changes
+	sess = base.Copy(&aws.Config{Region: aws.String(awsRegion)})
+	sessions[key+"|"+awsRegion] = sess
+	return sess, nil
+}
+
+// newCredentialSession creates the session holding the credentials of a profile and of the selected role
+func newCredentialSession(profileName string, awsRegion string, key string) (sess *session.Session, err error) {
+	sess, err = session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region: aws.String(awsRegion),
+		},
+		Profile:                 profileName,
+		SharedConfigState:       session.SharedConfigEnable,
+		AssumeRoleTokenProvider: stscreds.StdinTokenProvider,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if roleArn != "" {
+		provider := &stscreds.AssumeRoleProvider{
+			Client:          sts.New(sess),
+			RoleARN:         roleArn,
+			RoleSessionName: "pargolo-" + time.Now().UTC().Format("20060102150405"),
+			Duration:        stscreds.DefaultDuration,
+		}
+		if roleDuration > 0 {
+			provider.Duration = roleDuration
+		}
+		if externalID != "" {
+			provider.ExternalID = aws.String(externalID)
+		}
+		if mfaSerial != "" {
+			provider.SerialNumber = aws.String(mfaSerial)
+			provider.TokenProvider = stscreds.StdinTokenProvider
+		}
+		creds := credentials.NewCredentials(&cachedProvider{key: key, provider: provider})
+		sess = sess.Copy(&aws.Config{Credentials: creds})
+	}
+	return sess, nil
+}
+
+// accountOf returns the ID of the account the target profile belongs to, or the profile name if it can't be retrieved
+func accountOf(target awsTarget) string {
+	sess, err := createSessionFor(target)
+	if err == nil {
+		identity, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		if err == nil {
+			return *identity.Account
+		}
+	}
+	if target.Profile == "" {
+		return "default"
+	}
+	return target.Profile
+}
+
+// cachedProvider stores the assumed role credentials in the user cache directory,
+// so that following executions reuse them until they expire instead of asking a new MFA token
+type cachedProvider struct {
+	credentials.Expiry
+	key      string
+	provider credentials.Provider
+}
+
+type cachedCredentials struct {
+	Value      credentials.Value
+	Expiration time.Time
+}
+
+// Retrieve returns the cached credentials if still valid, otherwise it assumes the role again
+func (c *cachedProvider) Retrieve() (credentials.Value, error) {
+	file := c.cacheFile()
+	if data, err := ioutil.ReadFile(file); err == nil {
+		var cached cachedCredentials
+		if json.Unmarshal(data, &cached) == nil && time.Now().Add(time.Minute).Before(cached.Expiration) {
+			c.SetExpiration(cached.Expiration, time.Minute)
+			return cached.Value, nil
+		}
+	}
+
+	value, err := c.provider.Retrieve()
+	if err != nil {
+		return value, err
+	}
+	expiration := time.Now().Add(stscreds.DefaultDuration)
+	if expirer, ok := c.provider.(credentials.Expirer); ok {
+		expiration = expirer.ExpiresAt()
+	}
+	c.SetExpiration(expiration, time.Minute)
+
+	if data, err := json.Marshal(cachedCredentials{Value: value, Expiration: expiration}); err == nil {
+		if os.MkdirAll(filepath.Dir(file), 0700) == nil {
+			ioutil.WriteFile(file, data, 0600)
+		}
+	}
+	return value, nil
+}
+
+func (c *cachedProvider) cacheFile() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "pargolo", fmt.Sprintf("%x.json", sha1.Sum([]byte(c.key))))
+}
+
+// addCredentialFlags registers the AWS authentication flags on a subcommand
+func addCredentialFlags(flagset *flag.FlagSet) {
+	flagset.StringVar(&profile, "profile", "", "(optional) AWS profile")
+	flagset.StringVar(&region, "region", defaultRegion, "(optional) AWS region")
+	flagset.StringVar(&roleArn, "role-arn", "", "(optional) ARN of the role to assume")
+	flagset.StringVar(&externalID, "external-id", "", "(optional) External ID required to assume the role")
+	flagset.StringVar(&mfaSerial, "mfa-serial", "", "(optional) MFA device serial number or ARN, the token is asked on the terminal")
+	flagset.DurationVar(&roleDuration, "role-duration", 0, "(optional) Duration of the assumed role credentials, e.g. 1h")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// GetSingleParameter prints a single parameter, with raw only its value is printed.
+func GetSingleParameter(paramName string, raw bool) {
+	param, err := GetParameterByName(paramName)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if raw {
+		fmt.Println(param.Value)
+		return
+	}
+	fmt.Printf("%s %s %s (version %d)\n", param.Type, param.Name, param.Value, param.Version)
+}
+
+// SetSingleParameter writes a single parameter reading its value from the -value flag, the -file file or the standard input,
+// so that secrets never appear in the shell history. With ifVersion the write is refused unless the parameter has that version.
+// Parameter Store has no conditional write: the version is read right before the write, and a change made by someone else
+// between the two is only detected afterwards, when the value is already written.
+func SetSingleParameter(paramName string, paramType string, overwrite bool, ifVersion int64) {
+	paramValue, err := readValue()
+	if err != nil {
+		log.Fatalln("error reading value:", err)
+	}
+	if paramValue == "" {
+		log.Fatalln("the value can't be empty")
+	}
+
+	if ifVersion > 0 {
+		current, err := GetParameterByName(paramName)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if current.Version != ifVersion {
+			log.Fatalf("%s is at version %d, expected %d\n", paramName, current.Version, ifVersion)
+		}
+		overwrite = true
+	}
+
+	version, err := PutParameter(paramName, paramType, paramValue, overwrite)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if ifVersion > 0 && version != ifVersion+1 {
+		log.Fatalf("%s was modified by someone else right before the write: the value was written anyway as version %d, check the previous versions\n", paramName, version)
+	}
+	fmt.Printf("%s set to version %d\n", paramName, version)
+}
+
+func readValue() (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	if valueFile != "" {
+		data, err := ioutil.ReadFile(valueFile)
+		return string(data), err
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, "Value: ")
+		data, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(data), err
+	}
+	data, err := ioutil.ReadAll(os.Stdin)
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), err
+}
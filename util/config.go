@@ -0,0 +1,132 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFileName is the name of the project configuration file
+const ConfigFileName = ".pargolo.yaml"
+
+// DefaultPathTemplate is the default layout of project parameters
+const DefaultPathTemplate = "/{env}/{domain}/{project}"
+
+// Config holds the defaults read from a .pargolo.yaml file
+type Config struct {
+	Profile       string                       `yaml:"profile"`
+	Region        string                       `yaml:"region"`
+	Env           string                       `yaml:"env"`
+	Domain        string                       `yaml:"domain"`
+	Project       string                       `yaml:"project"`
+	PathTemplate  string                       `yaml:"pathTemplate"`
+	Layers        []string                     `yaml:"layers"`
+	ProtectedEnvs []string                     `yaml:"protectedEnvs"`
+	Environments  map[string]EnvironmentConfig `yaml:"environments"`
+	Groups        map[string]GroupConfig       `yaml:"groups"`
+
+	// File is the path the configuration was read from, empty if none was found
+	File string `yaml:"-"`
+}
+
+// EnvironmentConfig maps a named environment to the account and region hosting it
+type EnvironmentConfig struct {
+	Profile string `yaml:"profile"`
+	Region  string `yaml:"region"`
+}
+
+// GroupConfig is a named set of profiles and regions to search at once
+type GroupConfig struct {
+	Profiles []string `yaml:"profiles"`
+	Regions  []string `yaml:"regions"`
+}
+
+// NewConfig parses a YAML configuration
+func NewConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// FindConfig looks for the configuration file named by PARGOLO_CONFIG, then in the working directory and in the home directory.
+// An empty configuration is returned if none exists.
+func FindConfig() (*Config, error) {
+	candidates := []string{ConfigFileName}
+	if file, ok := os.LookupEnv("PARGOLO_CONFIG"); ok {
+		candidates = []string{file}
+	} else if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ConfigFileName))
+	}
+
+	for _, file := range candidates {
+		data, err := ioutil.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		config, err := NewConfig(data)
+		if err != nil {
+			return nil, err
+		}
+		config.File = file
+		return config, nil
+	}
+	return &Config{}, nil
+}
+
+// ProjectPath returns the path of the project parameters following the configured path template
+func (c *Config) ProjectPath(env string, domain string, project string) string {
+	template := c.PathTemplate
+	if template == "" {
+		template = DefaultPathTemplate
+	}
+	return ExpandPathTemplate(template, env, domain, project)
+}
+
+// ProjectDepth returns the number of segments of a project path following the configured path template
+func (c *Config) ProjectDepth() int {
+	return strings.Count(c.ProjectPath("env", "domain", "project"), "/")
+}
+
+// EnvironmentIndex returns the hierarchy level holding the environment following the configured path template
+func (c *Config) EnvironmentIndex() int {
+	if c.PathTemplate == "" {
+		return EnvironmentIndex(DefaultPathTemplate)
+	}
+	return EnvironmentIndex(c.PathTemplate)
+}
+
+// DefaultLayers are the inheritance layers, from the lowest to the highest precedence:
+// environment defaults, domain defaults and the project path template
+var DefaultLayers = []string{"/{env}/common", "/{env}/{domain}/common"}
+
+// LayerPaths returns the paths of the inheritance layers, from the lowest to the highest precedence
+func (c *Config) LayerPaths(env string, domain string, project string) []string {
+	var ret []string
+	if len(c.Layers) > 0 {
+		for _, layer := range c.Layers {
+			ret = append(ret, ExpandPathTemplate(layer, env, domain, project))
+		}
+		return ret
+	}
+	for _, layer := range DefaultLayers {
+		ret = append(ret, ExpandPathTemplate(layer, env, domain, project))
+	}
+	return append(ret, c.ProjectPath(env, domain, project))
+}
+
+// ExpandPathTemplate replaces the {env}, {domain} and {project} placeholders of a path template
+func ExpandPathTemplate(template string, env string, domain string, project string) string {
+	expanded := strings.NewReplacer("{env}", env, "{domain}", domain, "{project}", project).Replace(template)
+	for strings.Contains(expanded, "//") {
+		expanded = strings.Replace(expanded, "//", "/", -1)
+	}
+	return strings.TrimSuffix(expanded, "/")
+}
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	file, _ := ioutil.ReadFile("config/testConfig.yaml")
+
+	config, err := NewConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "shared", config.Profile)
+	assert.Equal(t, "prod-account", config.Environments["prod"].Profile)
+	assert.Equal(t, "eu-central-1", config.Environments["prod"].Region)
+	assert.Equal(t, []string{"dev-account", "prod-account"}, config.Groups["everywhere"].Profiles)
+	assert.Equal(t, "/payments/prod/api", config.ProjectPath("prod", config.Domain, config.Project))
+}
+
+func TestConfigUnknownFieldShouldReturnError(t *testing.T) {
+	_, err := NewConfig([]byte("profil: typo\n"))
+
+	assert.NotNil(t, err)
+}
+
+func TestDefaultProjectPath(t *testing.T) {
+	config := &Config{}
+
+	assert.Equal(t, "/dev/payments/api", config.ProjectPath("dev", "payments", "api"))
+	assert.Equal(t, "/dev/api", ExpandPathTemplate("/{env}/{domain}/{project}", "dev", "", "api"))
+	assert.Equal(t, 3, config.ProjectDepth())
+	assert.Equal(t, 0, config.EnvironmentIndex())
+
+	config.PathTemplate = "/{domain}/{env}/{project}"
+	assert.Equal(t, 1, config.EnvironmentIndex())
+}
+
+func TestFindConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pargolo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "custom.yaml")
+	ioutil.WriteFile(file, []byte("env: staging\n"), 0600)
+
+	os.Setenv("PARGOLO_CONFIG", file)
+	defer os.Unsetenv("PARGOLO_CONFIG")
+	config, err := FindConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "staging", config.Env)
+	assert.Equal(t, file, config.File)
+}
@@ -0,0 +1,296 @@
+package util
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultKeysToIgnore lists the configuration keys that are never turned into parameters
+var DefaultKeysToIgnore = []string{
+	"Environment",
+	"AWSAccessKey",
+	"AWSSecretKey",
+}
+
+// Naming strategies applied to every key segment
+const (
+	NamingPreserve = "preserve"
+	NamingLower    = "lower"
+	NamingKebab    = "kebab"
+	NamingSnake    = "snake"
+)
+
+// NamingStrategies lists the supported naming strategies
+var NamingStrategies = map[string]bool{
+	NamingPreserve: true,
+	NamingLower:    true,
+	NamingKebab:    true,
+	NamingSnake:    true,
+}
+
+type converter interface {
+	Convert(input []byte) ([]string, error)
+	ConvertWithValues(input []byte) ([]KeyValue, error)
+}
+
+// KeyValue is a flattened configuration key with its default value
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// ConverterOption customizes the behavior of a converter
+type ConverterOption func(*flattener)
+
+// WithScalars makes the converter emit non-empty scalars too, using their value as default
+func WithScalars() ConverterOption {
+	return func(c *flattener) {
+		c.includeScalars = true
+	}
+}
+
+// WithNaming sets the naming strategy of key segments: preserve, lower, kebab or snake
+func WithNaming(naming string) ConverterOption {
+	return func(c *flattener) {
+		c.naming = naming
+	}
+}
+
+// WithSeparator sets the separator between key segments
+func WithSeparator(separator string) ConverterOption {
+	return func(c *flattener) {
+		c.separator = separator
+	}
+}
+
+// WithKeysToIgnore replaces the keys to ignore, a pattern is matched case insensitively against
+// the key name or the whole key path and can contain glob characters
+func WithKeysToIgnore(patterns []string) ConverterOption {
+	return func(c *flattener) {
+		c.keysToIgnore = patterns
+	}
+}
+
+// flattener turns nested configuration values into parameter keys, it is shared by all converters
+type flattener struct {
+	includeScalars bool
+	naming         string
+	separator      string
+	keysToIgnore   []string
+}
+
+func newFlattener(options []ConverterOption) flattener {
+	f := flattener{
+		naming:       NamingLower,
+		separator:    "/",
+		keysToIgnore: DefaultKeysToIgnore,
+	}
+	for _, option := range options {
+		option(&f)
+	}
+	return f
+}
+
+// NewConverterForFile create a new converter chosen by the extension of the config file:
+// .json, .yaml, .yml, .toml, .properties or .env
+func NewConverterForFile(filename string, options ...ConverterOption) (converter, error) {
+	switch configFormat(filename) {
+	case "json":
+		return NewJSONToCsvConverter(options...), nil
+	case "yaml":
+		return NewYAMLToCsvConverter(options...), nil
+	case "toml":
+		return NewTOMLToCsvConverter(options...), nil
+	case "properties":
+		return NewPropertiesToCsvConverter(options...), nil
+	case "env":
+		return NewDotenvToCsvConverter(options...), nil
+	}
+	return nil, fmt.Errorf("unsupported config file %s", filename)
+}
+
+// IsSupportedConfigFile returns true if a converter exists for the config file extension
+func IsSupportedConfigFile(filename string) bool {
+	return configFormat(filename) != ""
+}
+
+func configFormat(filename string) string {
+	base := strings.ToLower(filepath.Base(filename))
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return "env"
+	}
+	switch filepath.Ext(base) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".properties":
+		return "properties"
+	case ".env":
+		return "env"
+	}
+	return ""
+}
+
+// normalize converts decoded YAML and TOML values to the types produced by encoding/json
+func normalize(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		ret := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			ret[fmt.Sprintf("%v", key)] = normalize(item)
+		}
+		return ret
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			ret[key] = normalize(item)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(typed))
+		for i, item := range typed {
+			ret[i] = normalize(item)
+		}
+		return ret
+	case []map[string]interface{}:
+		ret := make([]interface{}, len(typed))
+		for i, item := range typed {
+			ret[i] = normalize(item)
+		}
+		return ret
+	}
+	return value
+}
+
+func keysOf(rows []KeyValue) []string {
+	var ret []string
+	for _, row := range rows {
+		ret = append(ret, row.Key)
+	}
+	return ret
+}
+
+func (c *flattener) getRows(parents []string, jsonMap map[string]interface{}) []KeyValue {
+	var ret []KeyValue
+	keys := make([]string, 0, len(jsonMap))
+	for key := range jsonMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ret = append(ret, c.getValueRows(append(parents[:len(parents):len(parents)], key), jsonMap[key])...)
+	}
+	return ret
+}
+
+func (c *flattener) getValueRows(segments []string, value interface{}) []KeyValue {
+	if c.ignored(segments) {
+		return nil
+	}
+	var ret []KeyValue
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		ret = c.getRows(segments, typed)
+	case []interface{}:
+		if len(typed) == 0 {
+			ret = append(ret, KeyValue{Key: c.formatKey(segments)})
+		}
+		for i, item := range typed {
+			ret = append(ret, c.getValueRows(append(segments[:len(segments):len(segments)], strconv.Itoa(i)), item)...)
+		}
+	default:
+		scalar := formatScalar(typed)
+		if scalar == "" || c.includeScalars {
+			ret = append(ret, KeyValue{Key: c.formatKey(segments), Value: scalar})
+		}
+	}
+	return ret
+}
+
+func (c *flattener) ignored(segments []string) bool {
+	name := strings.ToLower(segments[len(segments)-1])
+	fullPath := strings.ToLower(strings.Join(segments, "/"))
+	for _, pattern := range c.keysToIgnore {
+		pattern = strings.ToLower(pattern)
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, fullPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *flattener) formatKey(segments []string) string {
+	formatted := make([]string, len(segments))
+	for i, segment := range segments {
+		formatted[i] = FormatSegment(c.naming, segment)
+	}
+	return strings.Join(formatted, c.separator)
+}
+
+// FormatSegment applies a naming strategy to a single key segment
+func FormatSegment(naming string, segment string) string {
+	switch naming {
+	case NamingPreserve:
+		return segment
+	case NamingKebab:
+		return strings.Join(splitWords(segment), "-")
+	case NamingSnake:
+		return strings.Join(splitWords(segment), "_")
+	}
+	return strings.ToLower(segment)
+}
+
+// splitWords splits a camel case, kebab case or snake case identifier into lower case words
+func splitWords(segment string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(segment)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+	for i, r := range runes {
+		if r == '-' || r == '_' || r == ' ' || r == '.' {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+// formatScalar formats a decoded JSON scalar, null is an empty string
+func formatScalar(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case bool:
+		return strconv.FormatBool(typed)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,153 @@
+package util
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var configFormats = map[string]string{
+	"json":       "json",
+	"yaml":       "yaml",
+	"toml":       "toml",
+	"properties": "properties",
+	"env":        "env",
+}
+
+func convertFixture(t *testing.T, format string, name string) []string {
+	inputPath := format + "/" + name + "." + configFormats[format]
+	file, err := ioutil.ReadFile(inputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConverterForFile(inputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actualCsv, err := c.Convert(file)
+	if err != nil {
+		t.Fatalf("%s: %v", inputPath, err)
+	}
+	return actualCsv
+}
+
+func TestAllFormatsOneVariableOneLevel(t *testing.T) {
+	for format := range configFormats {
+		actualCsv := convertFixture(t, format, "testOneVariableOneLevel")
+
+		assert.Equal(t, 0, len(actualCsv), format)
+	}
+}
+
+func TestAllFormatsOneVariableTwoLevel(t *testing.T) {
+	for format := range configFormats {
+		actualCsv := convertFixture(t, format, "testOneVariableTwoLevel")
+
+		assert.Equal(t, []string{`sentry/sentrydsn`}, actualCsv, format)
+	}
+}
+
+func TestAllFormatsOneVariableThreeLevel(t *testing.T) {
+	for format := range configFormats {
+		actualCsv := convertFixture(t, format, "testOneVariableThreeLevel")
+
+		assert.Equal(t, []string{`sentry/sentrydsn1/sentrydsn2`}, actualCsv, format)
+	}
+}
+
+func TestAllFormatsMultilineOneVariableOneLevel(t *testing.T) {
+	for format := range configFormats {
+		actual := strings.Join(convertFixture(t, format, "testMultilineOneVariableOneLevel"), "|")
+
+		assert.Equal(t, true, strings.Contains(actual, `oauth2/secretkey`), format)
+		assert.Equal(t, true, strings.Contains(actual, `oauth2/clientsecret`), format)
+		assert.Equal(t, true, strings.Contains(actual, `oauth2/cacheredis/endpoint`), format)
+		assert.Equal(t, false, strings.Contains(actual, `environment`), format)
+		assert.Equal(t, true, strings.Contains(actual, `webapp/port`), format)
+		assert.Equal(t, true, strings.Contains(actual, `log/rollingfile/file`), format)
+	}
+}
+
+func TestAllFormatsMultilineOneVariableSameLevel(t *testing.T) {
+	for format := range configFormats {
+		actual := strings.Join(convertFixture(t, format, "testMultilineOneVariableSameLevel"), "|")
+
+		assert.Equal(t, true, strings.Contains(actual, `oauth2/secretkey`), format)
+		assert.Equal(t, true, strings.Contains(actual, `oauth2/clientsecret`), format)
+		assert.Equal(t, true, strings.Contains(actual, `oauth2/cacheredis/endpoint`), format)
+	}
+}
+
+func TestAllFormatsRealCase(t *testing.T) {
+	expected := strings.Join(convertFixture(t, "json", "testRealCase"), "|")
+	for format := range configFormats {
+		actualCsv := convertFixture(t, format, "testRealCase")
+
+		for _, key := range actualCsv {
+			assert.Equal(t, true, strings.Contains(expected, key), format+" "+key)
+		}
+		assert.Equal(t, len(strings.Split(expected, "|")), len(actualCsv), format)
+	}
+}
+
+func TestNewConverterForFile(t *testing.T) {
+	for _, filename := range []string{"appsettings.json", "application.yml", "config.toml", "application.properties", ".env", ".env.production", "local.env"} {
+		_, err := NewConverterForFile(filename)
+		assert.Nil(t, err, filename)
+	}
+	_, err := NewConverterForFile("config.xml")
+	assert.NotNil(t, err)
+}
+
+func TestPropertiesSyntax(t *testing.T) {
+	input := "# comment\n! comment\nspring.datasource.url = jdbc:mysql://localhost/db\nspring.datasource.password:\nhosts[0]=\nhosts[1] backup\\\n  .local\nescaped\\=key=\\u0041\n"
+
+	rows, err := NewPropertiesToCsvConverter(WithScalars(), WithNaming(NamingPreserve)).ConvertWithValues([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []KeyValue{
+		{Key: "spring/datasource/url", Value: "jdbc:mysql://localhost/db"},
+		{Key: "spring/datasource/password", Value: ""},
+		{Key: "hosts/0", Value: ""},
+		{Key: "hosts/1", Value: "backup.local"},
+		{Key: "escaped=key", Value: "A"},
+	}, rows)
+}
+
+func TestDotenvSyntax(t *testing.T) {
+	input := "# comment\nexport DATABASE_URL=postgres://localhost/db # inline comment\nOAuth2__ClientSecret=\nGREETING=\"hello\\nworld\"\nRAW='a # b'\n"
+
+	rows, err := NewDotenvToCsvConverter(WithScalars(), WithNaming(NamingPreserve)).ConvertWithValues([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []KeyValue{
+		{Key: "DATABASE_URL", Value: "postgres://localhost/db"},
+		{Key: "OAuth2/ClientSecret", Value: ""},
+		{Key: "GREETING", Value: "hello\nworld"},
+		{Key: "RAW", Value: "a # b"},
+	}, rows)
+
+	_, err = NewDotenvToCsvConverter().Convert([]byte("NOT A PAIR\n"))
+	assert.NotNil(t, err)
+}
+
+func TestYAMLArraysAndNulls(t *testing.T) {
+	input := "hosts:\n  - \"\"\n  - backup.local\nproxy: ~\nredis:\n  port: 6379\n"
+
+	rows, err := NewYAMLToCsvConverter(WithScalars()).ConvertWithValues([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := map[string]string{}
+	for _, row := range rows {
+		actual[row.Key] = row.Value
+	}
+
+	assert.Equal(t, map[string]string{"hosts/0": "", "hosts/1": "backup.local", "proxy": "", "redis/port": "6379"}, actual)
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CanonicalizeRecords sorts name,type,value records by name and removes exact duplicates.
+// Rows without exactly 3 columns or names defined twice with different type or value are returned as violations.
+func CanonicalizeRecords(records [][]string) ([][]string, []Violation) {
+	var ret [][]string
+	var violations []Violation
+	seen := make(map[string]int)
+
+	for i, row := range records {
+		if len(row) != 3 {
+			violations = append(violations, Violation{Row: i + 1, Name: strings.Join(row, ","), Message: "expected 3 columns: name,type,value"})
+			continue
+		}
+		if first, ok := seen[row[0]]; ok {
+			if records[first-1][1] != row[1] || records[first-1][2] != row[2] {
+				violations = append(violations, Violation{Row: i + 1, Name: row[0], Message: fmt.Sprintf("conflicts with row %d", first)})
+			}
+			continue
+		}
+		seen[row[0]] = i + 1
+		ret = append(ret, row)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i][0] < ret[j][0] })
+	return ret, violations
+}
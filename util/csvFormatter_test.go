@@ -0,0 +1,41 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalizeRecords(t *testing.T) {
+	records := [][]string{
+		{"/dev/dom/proj/webapp/port", "String", "8080"},
+		{"/dev/common/redis/endpoint", "String", "redis.local"},
+		{"/dev/dom/proj/webapp/port", "String", "8080"},
+		{"/dev/dom/proj/log/level", "String", "Debug"},
+	}
+
+	actual, violations := CanonicalizeRecords(records)
+
+	assert.Equal(t, 0, len(violations))
+	assert.Equal(t, [][]string{
+		{"/dev/common/redis/endpoint", "String", "redis.local"},
+		{"/dev/dom/proj/log/level", "String", "Debug"},
+		{"/dev/dom/proj/webapp/port", "String", "8080"},
+	}, actual)
+}
+
+func TestCanonicalizeRecordsConflicts(t *testing.T) {
+	records := [][]string{
+		{"/dev/dom/proj/webapp/port", "String", "8080"},
+		{"/dev/dom/proj/webapp/port", "String", "8081"},
+		{"/dev/dom/proj/log/level"},
+		{"/dev/dom/proj/log/format", "String", "json", "extra"},
+	}
+
+	_, violations := CanonicalizeRecords(records)
+
+	assert.Equal(t, 3, len(violations))
+	assert.Equal(t, "row 2 /dev/dom/proj/webapp/port: conflicts with row 1", violations[0].String())
+	assert.Equal(t, 3, violations[1].Row)
+	assert.Equal(t, "row 4 /dev/dom/proj/log/format,String,json,extra: expected 3 columns: name,type,value", violations[2].String())
+}
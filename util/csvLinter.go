@@ -0,0 +1,122 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Limits enforced by AWS Systems Manager Parameter Store
+const (
+	MaxNameLength      = 2048
+	MaxHierarchyLevels = 15
+)
+
+// ValueSizeLimits maps a parameter tier to the maximum value size in bytes
+var ValueSizeLimits = map[string]int{
+	"Standard": 4096,
+	"Advanced": 8192,
+}
+
+// ParameterTypes lists the valid parameter types
+var ParameterTypes = map[string]bool{
+	"String":       true,
+	"StringList":   true,
+	"SecureString": true,
+}
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_.\-/]+$`)
+
+// LintRecords checks name,type,value records for problems that would make an upload fail or misbehave,
+// envIndex is the hierarchy level holding the environment, see EnvironmentIndex
+func LintRecords(records [][]string, tier string, envIndex int) []Violation {
+	var ret []Violation
+	seen := make(map[string]int)
+	envs := make(map[string]int)
+	maxValueSize, ok := ValueSizeLimits[tier]
+	if !ok {
+		maxValueSize = ValueSizeLimits["Standard"]
+	}
+
+	for i, row := range records {
+		add := func(message string) {
+			ret = append(ret, Violation{Row: i + 1, Name: row[0], Message: message})
+		}
+		if len(row) < 3 {
+			ret = append(ret, Violation{Row: i + 1, Name: strings.Join(row, ","), Message: "expected 3 columns: name,type,value"})
+			continue
+		}
+		name, paramType, value := row[0], row[1], row[2]
+
+		if first, ok := seen[name]; ok {
+			add(fmt.Sprintf("duplicate name, first defined at row %d", first))
+		} else {
+			seen[name] = i + 1
+		}
+		if !ParameterTypes[paramType] {
+			add(fmt.Sprintf("invalid type %q, expected String, StringList or SecureString", paramType))
+		}
+		for _, message := range lintName(name) {
+			add(message)
+		}
+		if value == "" {
+			add("empty value")
+		} else if len(value) > maxValueSize {
+			add(fmt.Sprintf("value is %d bytes, %s tier allows %d", len(value), tier, maxValueSize))
+		}
+		if _, _, ok, err := ParseGeneratePlaceholder(value); ok {
+			if err != nil {
+				add(err.Error())
+			}
+			if paramType != "SecureString" {
+				add("generated values must be of type SecureString")
+			}
+		}
+		if strings.TrimSpace(name) != name {
+			add("leading or trailing whitespace in name")
+		}
+		if value != "" && strings.TrimSpace(value) != value {
+			add("leading or trailing whitespace in value")
+		}
+		if env := EnvironmentOf(name, envIndex); env != "" {
+			if _, ok := envs[env]; !ok {
+				envs[env] = i + 1
+			}
+		}
+	}
+
+	if len(envs) > 1 {
+		var list []string
+		for env, row := range envs {
+			list = append(list, fmt.Sprintf("%s (row %d)", env, row))
+		}
+		sort.Strings(list)
+		ret = append(ret, Violation{Name: "environments", Message: "mixed environments: " + strings.Join(list, ", ")})
+	}
+	return ret
+}
+
+func lintName(name string) []string {
+	var ret []string
+	if len(name) > MaxNameLength {
+		ret = append(ret, fmt.Sprintf("name is %d characters, max %d", len(name), MaxNameLength))
+	}
+	if !validName.MatchString(name) {
+		ret = append(ret, "name contains characters other than a-zA-Z0-9_.-/")
+	}
+	if strings.Contains(name, "/") && !strings.HasPrefix(name, "/") {
+		ret = append(ret, "hierarchical name must begin with /")
+	}
+	if strings.Contains(name, "//") || strings.HasSuffix(name, "/") {
+		ret = append(ret, "name contains an empty hierarchy level")
+	}
+	if levels := strings.Count(strings.TrimPrefix(name, "/"), "/") + 1; levels > MaxHierarchyLevels {
+		ret = append(ret, fmt.Sprintf("name has %d hierarchy levels, max %d", levels, MaxHierarchyLevels))
+	}
+	lower := strings.ToLower(strings.TrimPrefix(name, "/"))
+	if strings.HasPrefix(lower, "aws") || strings.HasPrefix(lower, "ssm") {
+		ret = append(ret, "name cannot begin with aws or ssm")
+	}
+	return ret
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintRealCase(t *testing.T) {
+	violations := LintRecords(readTestCsv(t, "csv/testLint.csv"), "Standard", 0)
+
+	var actual []string
+	for _, violation := range violations {
+		actual = append(actual, violation.String())
+	}
+	joined := strings.Join(actual, "|")
+
+	assert.Equal(t, true, strings.Contains(joined, "row 2 /dev/dom/proj/webapp/port: duplicate name, first defined at row 1"))
+	assert.Equal(t, true, strings.Contains(joined, `row 3 /dev/dom/proj/sentry/sentrydsn: invalid type "Secure"`))
+	assert.Equal(t, true, strings.Contains(joined, "row 4 /dev/dom/proj/oauth2/clientsecret: empty value"))
+	assert.Equal(t, true, strings.Contains(joined, "row 5 /dev/dom/proj/my key: name contains characters"))
+	assert.Equal(t, true, strings.Contains(joined, "row 6 /prod/dom/proj/log/level: leading or trailing whitespace in value"))
+	assert.Equal(t, true, strings.Contains(joined, "environments: mixed environments: dev (row 1), prod (row 6)"))
+	assert.Equal(t, 6, len(violations))
+}
+
+func TestLintShortRowShouldNotPanic(t *testing.T) {
+	violations := LintRecords([][]string{{"/dev/dom/proj/webapp/port"}}, "Standard", 0)
+
+	assert.Equal(t, 1, len(violations))
+	assert.Equal(t, "row 1 /dev/dom/proj/webapp/port: expected 3 columns: name,type,value", violations[0].String())
+}
+
+func TestLintNameConstraints(t *testing.T) {
+	deep := "/" + strings.Repeat("a/", MaxHierarchyLevels) + "b"
+
+	assert.Equal(t, 1, len(lintName(deep)))
+	assert.Equal(t, 1, len(lintName("/"+strings.Repeat("a", MaxNameLength))))
+	assert.Equal(t, 1, len(lintName("relative/name")))
+	assert.Equal(t, 1, len(lintName("/aws/reserved")))
+	assert.Equal(t, 0, len(lintName("/dev/dom/proj/webapp/port")))
+}
+
+func TestLintValueSizePerTier(t *testing.T) {
+	records := [][]string{{"/dev/dom/proj/cert", "SecureString", strings.Repeat("x", 5000)}}
+
+	assert.Equal(t, 1, len(LintRecords(records, "Standard", 0)))
+	assert.Equal(t, 0, len(LintRecords(records, "Advanced", 0)))
+}
+
+func TestLintGeneratePlaceholders(t *testing.T) {
+	records := [][]string{
+		{"/dev/dom/proj/db/password", "SecureString", "!generate:32:hex"},
+		{"/dev/dom/proj/api/key", "SecureString", "!generate:3two"},
+		{"/dev/dom/proj/api/token", "String", "!generate"},
+	}
+
+	violations := LintRecords(records, "Standard", 0)
+
+	assert.Equal(t, 2, len(violations))
+	assert.Equal(t, 2, violations[0].Row)
+	assert.Equal(t, true, strings.Contains(violations[0].Message, "invalid placeholder"))
+	assert.Equal(t, "row 3 /dev/dom/proj/api/token: generated values must be of type SecureString", violations[1].String())
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type dotenvToCsvConverter struct {
+	flattener
+}
+
+// NewDotenvToCsvConverter create a new converter for .env files,
+// a double underscore separates key segments as in .NET environment variables
+func NewDotenvToCsvConverter(options ...ConverterOption) converter {
+	return &dotenvToCsvConverter{newFlattener(options)}
+}
+
+// Convert return a slice of strings with converted data
+func (c *dotenvToCsvConverter) Convert(inputEnv []byte) ([]string, error) {
+	rows, err := c.ConvertWithValues(inputEnv)
+	return keysOf(rows), err
+}
+
+// ConvertWithValues return a slice of keys with their default values
+func (c *dotenvToCsvConverter) ConvertWithValues(inputEnv []byte) ([]KeyValue, error) {
+	var ret []KeyValue
+	scanner := bufio.NewScanner(bytes.NewReader(inputEnv))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		separator := strings.Index(line, "=")
+		if separator < 1 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+		key := strings.TrimSpace(line[:separator])
+		value, err := unquoteDotenv(strings.TrimSpace(line[separator+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		ret = append(ret, c.getValueRows(strings.Split(key, "__"), value)...)
+	}
+	return ret, scanner.Err()
+}
+
+func unquoteDotenv(value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+	switch value[0] {
+	case '"':
+		end := strings.LastIndex(value, `"`)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return strconv.Unquote(value[:end+1])
+	case '\'':
+		end := strings.LastIndex(value, "'")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return value[1:end], nil
+	}
+	if comment := strings.Index(value, " #"); comment >= 0 {
+		value = strings.TrimSpace(value[:comment])
+	}
+	return value, nil
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"sort"
+	"strings"
+)
+
+// EnvironmentIndex returns the hierarchy level, starting from 0, holding the environment in a path template.
+// The first level is returned when the template has no {env} segment.
+func EnvironmentIndex(template string) int {
+	for i, segment := range strings.Split(strings.Trim(template, "/"), "/") {
+		if segment == "{env}" {
+			return i
+		}
+	}
+	return 0
+}
+
+// EnvironmentOf returns the environment of a parameter name, that is its hierarchy level at index, see EnvironmentIndex
+func EnvironmentOf(name string, index int) string {
+	if !strings.HasPrefix(name, "/") {
+		return ""
+	}
+	segments := strings.Split(strings.TrimPrefix(name, "/"), "/")
+	if index >= len(segments) {
+		return ""
+	}
+	return segments[index]
+}
+
+// TargetEnvironments returns the environments targeted by name,type,value records with the 1-based rows targeting each,
+// the environment is the hierarchy level at index
+func TargetEnvironments(records [][]string, index int) map[string][]int {
+	ret := make(map[string][]int)
+	for i, row := range records {
+		if len(row) == 0 {
+			continue
+		}
+		env := EnvironmentOf(row[0], index)
+		ret[env] = append(ret[env], i+1)
+	}
+	return ret
+}
+
+// SplitList splits a comma separated list, dropping blanks
+func SplitList(list string) []string {
+	var ret []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+// SortedKeys returns the keys of a map of rows in alphabetical order
+func SortedKeys(m map[string][]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
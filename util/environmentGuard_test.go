@@ -0,0 +1,45 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironmentOf(t *testing.T) {
+	assert.Equal(t, "prod", EnvironmentOf("/prod/dom/proj/webapp/port", 0))
+	assert.Equal(t, "prod", EnvironmentOf("/prod", 0))
+	assert.Equal(t, "", EnvironmentOf("webapp/port", 0))
+	assert.Equal(t, "prod", EnvironmentOf("/dom/prod/proj/webapp/port", 1))
+	assert.Equal(t, "", EnvironmentOf("/dom", 1))
+}
+
+func TestEnvironmentIndex(t *testing.T) {
+	assert.Equal(t, 0, EnvironmentIndex(DefaultPathTemplate))
+	assert.Equal(t, 1, EnvironmentIndex("/{domain}/{env}/{project}"))
+	assert.Equal(t, 0, EnvironmentIndex("/{domain}/{project}"))
+}
+
+func TestTargetEnvironments(t *testing.T) {
+	targets := TargetEnvironments([][]string{
+		{"/dev/dom/proj/webapp/port", "String", "80"},
+		{"/prod/dom/proj/webapp/port", "String", "80"},
+		{"/dev/common/redis/endpoint", "String", "localhost"},
+	}, 0)
+
+	assert.Equal(t, []string{"dev", "prod"}, SortedKeys(targets))
+	assert.Equal(t, []int{1, 3}, targets["dev"])
+	assert.Equal(t, []int{2}, targets["prod"])
+
+	targets = TargetEnvironments([][]string{
+		{"/dom/dev/proj/webapp/port", "String", "80"},
+		{"/dom/prod/proj/webapp/port", "String", "80"},
+	}, 1)
+
+	assert.Equal(t, []string{"dev", "prod"}, SortedKeys(targets))
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{"prod", "production"}, SplitList(" prod, ,production"))
+	assert.Equal(t, 0, len(SplitList("")))
+}
@@ -0,0 +1,169 @@
+package util
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// MissingValue is shown in the matrix cells of keys missing from an environment
+const MissingValue = "(missing)"
+
+// MatrixFormats lists the supported matrix output formats
+var MatrixFormats = map[string]bool{
+	"text": true,
+	"csv":  true,
+	"html": true,
+}
+
+// Matrix compares the keys of a project across environments
+type Matrix struct {
+	Envs []string
+	Rows []MatrixRow
+}
+
+// MatrixRow holds the value of a relative key in every environment
+type MatrixRow struct {
+	Key     string
+	Cells   []MatrixCell
+	Missing bool
+	Differs bool
+}
+
+// MatrixCell is the value of a key in an environment
+type MatrixCell struct {
+	Type    string
+	Value   string
+	Missing bool
+}
+
+// NewMatrix builds the matrix from one layer per environment, keys are relative to the layer path
+func NewMatrix(envs []string, layers []Layer) Matrix {
+	cells := make(map[string][]MatrixCell)
+	for i, layer := range layers {
+		prefix := strings.TrimSuffix(layer.Path, "/") + "/"
+		for _, row := range layer.Records {
+			if len(row) < 3 || !strings.HasPrefix(row[0], prefix) {
+				continue
+			}
+			key := strings.TrimPrefix(row[0], prefix)
+			if _, ok := cells[key]; !ok {
+				cells[key] = make([]MatrixCell, len(layers))
+				for j := range cells[key] {
+					cells[key][j].Missing = true
+				}
+			}
+			cells[key][i] = MatrixCell{Type: row[1], Value: row[2]}
+		}
+	}
+
+	matrix := Matrix{Envs: envs}
+	for key, row := range cells {
+		matrixRow := MatrixRow{Key: key, Cells: row}
+		first := -1
+		for i, cell := range row {
+			if cell.Missing {
+				matrixRow.Missing = true
+				continue
+			}
+			if first < 0 {
+				first = i
+			} else if cell.Value != row[first].Value || cell.Type != row[first].Type {
+				matrixRow.Differs = true
+			}
+		}
+		matrix.Rows = append(matrix.Rows, matrixRow)
+	}
+	sort.Slice(matrix.Rows, func(i, j int) bool { return matrix.Rows[i].Key < matrix.Rows[j].Key })
+	return matrix
+}
+
+func (row MatrixRow) status() string {
+	switch {
+	case row.Missing && row.Differs:
+		return "MISSING,DIFFERS"
+	case row.Missing:
+		return "MISSING"
+	case row.Differs:
+		return "DIFFERS"
+	}
+	return "SAME"
+}
+
+func (cell MatrixCell) display(reveal bool) string {
+	if cell.Missing {
+		return MissingValue
+	}
+	if reveal {
+		return cell.Value
+	}
+	return MaskValue(cell.Type, cell.Value)
+}
+
+// WriteMatrix writes the matrix in the given format: text, csv or html.
+// Unless reveal is true SecureString values are masked.
+func WriteMatrix(w io.Writer, format string, matrix Matrix, reveal bool) error {
+	switch format {
+	case "", "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "\tKEY\t%s\n", strings.ToUpper(strings.Join(matrix.Envs, "\t")))
+		for _, row := range matrix.Rows {
+			marker := ""
+			if row.Missing || row.Differs {
+				marker = "!"
+			}
+			values := make([]string, len(row.Cells))
+			for i, cell := range row.Cells {
+				values[i] = cell.display(reveal)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", marker, row.Key, strings.Join(values, "\t"))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(append(append([]string{"key"}, matrix.Envs...), "status"))
+		for _, row := range matrix.Rows {
+			record := []string{row.Key}
+			for _, cell := range row.Cells {
+				record = append(record, cell.display(reveal))
+			}
+			cw.Write(append(record, row.status()))
+		}
+		cw.Flush()
+		return cw.Error()
+	case "html":
+		lines := []string{
+			"<!DOCTYPE html>",
+			"<html><head><meta charset=\"utf-8\"><title>pargolo matrix</title><style>",
+			"table{border-collapse:collapse;font-family:monospace}td,th{border:1px solid #ccc;padding:4px 8px}",
+			".missing{background:#f8d7da}.differs{background:#fff3cd}",
+			"</style></head><body><table>",
+			"<tr><th>Key</th>",
+		}
+		for _, env := range matrix.Envs {
+			lines[len(lines)-1] += "<th>" + html.EscapeString(env) + "</th>"
+		}
+		lines[len(lines)-1] += "</tr>"
+		for _, row := range matrix.Rows {
+			line := "<tr><td>" + html.EscapeString(row.Key) + "</td>"
+			for _, cell := range row.Cells {
+				class := ""
+				if cell.Missing {
+					class = " class=\"missing\""
+				} else if row.Differs {
+					class = " class=\"differs\""
+				}
+				line += "<td" + class + ">" + html.EscapeString(cell.display(reveal)) + "</td>"
+			}
+			lines = append(lines, line+"</tr>")
+		}
+		lines = append(lines, "</table></body></html>")
+		_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+		return err
+	}
+	return fmt.Errorf("unknown format %s", format)
+}
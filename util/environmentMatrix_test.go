@@ -0,0 +1,58 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testMatrixLayers = []Layer{
+	{Path: "/dev/dom/proj", Records: [][]string{
+		{"/dev/dom/proj/webapp/port", "String", "8080"},
+		{"/dev/dom/proj/db/password", "SecureString", "dev-secret"},
+		{"/dev/dom/proj/log/level", "String", "Debug"},
+	}},
+	{Path: "/prod/dom/proj", Records: [][]string{
+		{"/prod/dom/proj/webapp/port", "String", "8080"},
+		{"/prod/dom/proj/db/password", "SecureString", "prod-secret"},
+	}},
+}
+
+func TestNewMatrix(t *testing.T) {
+	matrix := NewMatrix([]string{"dev", "prod"}, testMatrixLayers)
+
+	assert.Equal(t, 3, len(matrix.Rows))
+	assert.Equal(t, "db/password", matrix.Rows[0].Key)
+	assert.Equal(t, "DIFFERS", matrix.Rows[0].status())
+	assert.Equal(t, "log/level", matrix.Rows[1].Key)
+	assert.Equal(t, "MISSING", matrix.Rows[1].status())
+	assert.Equal(t, true, matrix.Rows[1].Cells[1].Missing)
+	assert.Equal(t, "SAME", matrix.Rows[2].status())
+}
+
+func TestWriteMatrixCsv(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteMatrix(&buffer, "csv", NewMatrix([]string{"dev", "prod"}, testMatrixLayers), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "key,dev,prod,status\n"+
+		"db/password,********,********,DIFFERS\n"+
+		"log/level,Debug,(missing),MISSING\n"+
+		"webapp/port,8080,8080,SAME\n", buffer.String())
+}
+
+func TestWriteMatrixHtml(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteMatrix(&buffer, "html", NewMatrix([]string{"dev", "prod"}, testMatrixLayers), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := buffer.String()
+
+	assert.Equal(t, true, strings.Contains(actual, `<td class="differs">prod-secret</td>`))
+	assert.Equal(t, true, strings.Contains(actual, `<td class="missing">(missing)</td>`))
+}
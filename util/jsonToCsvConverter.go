@@ -2,55 +2,29 @@ package util
 
 import (
 	"encoding/json"
-	"fmt"
-	"reflect"
-	"strings"
 )
 
-var keysToIgnore = map[string]bool{
-	"Environment":  true,
-	"AWSAccessKey": true,
-	"AWSSecretKey": true,
+type jsonToCsvConverter struct {
+	flattener
 }
 
-type converter interface {
-	Convert(inputJSON []byte) ([]string, error)
-}
-
-type jsonToCsvConverter struct{}
-
 // NewJSONToCsvConverter create a new converter
-func NewJSONToCsvConverter() converter {
-	return &jsonToCsvConverter{}
+func NewJSONToCsvConverter(options ...ConverterOption) converter {
+	return &jsonToCsvConverter{newFlattener(options)}
 }
 
 // Convert return a slice of strings with converted data
 func (c *jsonToCsvConverter) Convert(inputJSON []byte) ([]string, error) {
-	var ret []string
+	rows, err := c.ConvertWithValues(inputJSON)
+	return keysOf(rows), err
+}
+
+// ConvertWithValues return a slice of keys with their default values
+func (c *jsonToCsvConverter) ConvertWithValues(inputJSON []byte) ([]KeyValue, error) {
 	jsonMap := map[string]interface{}{}
 	err := json.Unmarshal(inputJSON, &jsonMap)
 	if err != nil {
-		return ret, err
-	}
-	ret = c.getRows(jsonMap)
-	return ret, nil
-}
-
-func (c *jsonToCsvConverter) getRows(jsonMap map[string]interface{}) []string {
-	var ret []string
-	for key, value := range jsonMap {
-		finalKey := key
-		kind := reflect.TypeOf(value).Kind()
-		if kind != reflect.Map {
-			if fmt.Sprintf("%v", value) == "" && !keysToIgnore[finalKey] {
-				ret = append(ret, strings.ToLower(finalKey))
-			}
-		} else {
-			for _, partialKey := range c.getRows(value.(map[string]interface{})) {
-				finalKey = fmt.Sprintf("%s/%s", key, partialKey)
-				ret = append(ret, strings.ToLower(finalKey))
-			}
-		}
+		return nil, err
 	}
-	return ret
+	return c.getRows(nil, jsonMap), nil
 }
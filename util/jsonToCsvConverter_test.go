@@ -129,3 +129,88 @@ func TestRealCase(t *testing.T) {
 	assert.Equal(t, true, strings.Contains(actual, `serviceendpoints/endpoint1/endpoint`))
 	assert.Equal(t, true, strings.Contains(actual, `serviceendpoints/endpoint2/endpoint`))
 }
+
+func TestArraysAndNulls(t *testing.T) {
+	inputPath := "json/testArraysAndNulls.json"
+	file, _ := ioutil.ReadFile(inputPath)
+
+	actualCsv, err := NewJSONToCsvConverter().Convert(file)
+	if err != nil {
+		t.Fail()
+	}
+	actual := "|" + strings.Join(actualCsv, "|") + "|"
+
+	assert.Equal(t, false, strings.Contains(actual, `|environment|`))
+	assert.Equal(t, true, strings.Contains(actual, `|hosts/0|`))
+	assert.Equal(t, false, strings.Contains(actual, `|hosts/1|`))
+	assert.Equal(t, true, strings.Contains(actual, `|endpoints/0/url|`))
+	assert.Equal(t, true, strings.Contains(actual, `|endpoints/1/name|`))
+	assert.Equal(t, true, strings.Contains(actual, `|endpoints/1/url|`))
+	assert.Equal(t, true, strings.Contains(actual, `|proxy|`))
+	assert.Equal(t, true, strings.Contains(actual, `|allowedorigins|`))
+	assert.Equal(t, false, strings.Contains(actual, `|maxretryattempts|`))
+	assert.Equal(t, 6, len(actualCsv))
+}
+
+func TestWithScalars(t *testing.T) {
+	inputPath := "json/testArraysAndNulls.json"
+	file, _ := ioutil.ReadFile(inputPath)
+
+	rows, err := NewJSONToCsvConverter(WithScalars()).ConvertWithValues(file)
+	if err != nil {
+		t.Fail()
+	}
+	actual := map[string]string{}
+	for _, row := range rows {
+		actual[row.Key] = row.Value
+	}
+
+	assert.Equal(t, "backup.local", actual["hosts/1"])
+	assert.Equal(t, "orders", actual["endpoints/0/name"])
+	assert.Equal(t, "5", actual["maxretryattempts"])
+	assert.Equal(t, "2.5", actual["timeout"])
+	assert.Equal(t, "false", actual["trackresponse"])
+	assert.Equal(t, "", actual["proxy"])
+	_, ok := actual["environment"]
+	assert.Equal(t, false, ok)
+}
+
+func TestNamingStrategies(t *testing.T) {
+	inputPath := "json/testRealCase.json"
+	file, _ := ioutil.ReadFile(inputPath)
+
+	preserve, _ := NewJSONToCsvConverter(WithNaming(NamingPreserve)).Convert(file)
+	kebab, _ := NewJSONToCsvConverter(WithNaming(NamingKebab)).Convert(file)
+	snake, _ := NewJSONToCsvConverter(WithNaming(NamingSnake), WithSeparator("__")).Convert(file)
+
+	assert.Equal(t, true, strings.Contains(strings.Join(preserve, "|"), `ConsoleCredentials/Password`))
+	assert.Equal(t, true, strings.Contains(strings.Join(kebab, "|"), `console-credentials/password`))
+	assert.Equal(t, true, strings.Contains(strings.Join(kebab, "|"), `sentry/sentry-dsn`))
+	assert.Equal(t, true, strings.Contains(strings.Join(snake, "|"), `console_credentials__password`))
+}
+
+func TestFormatSegment(t *testing.T) {
+	assert.Equal(t, "OAuth2Enable", FormatSegment(NamingPreserve, "OAuth2Enable"))
+	assert.Equal(t, "oauth2enable", FormatSegment(NamingLower, "OAuth2Enable"))
+	assert.Equal(t, "o-auth2-enable", FormatSegment(NamingKebab, "OAuth2Enable"))
+	assert.Equal(t, "api_key", FormatSegment(NamingSnake, "APIKey"))
+	assert.Equal(t, "maximum-file-size-mb", FormatSegment(NamingKebab, "MaximumFileSizeMB"))
+	assert.Equal(t, "max_size_roll_backups", FormatSegment(NamingSnake, "max-size-roll-backups"))
+}
+
+func TestKeysToIgnorePatterns(t *testing.T) {
+	inputPath := "json/testRealCase.json"
+	file, _ := ioutil.ReadFile(inputPath)
+
+	actualCsv, err := NewJSONToCsvConverter(WithKeysToIgnore([]string{"*redis", "oauth2/*", "Sentry"})).Convert(file)
+	if err != nil {
+		t.Fail()
+	}
+	actual := strings.Join(actualCsv, "|")
+
+	assert.Equal(t, true, strings.Contains(actual, `environment`))
+	assert.Equal(t, false, strings.Contains(actual, `redis/`))
+	assert.Equal(t, false, strings.Contains(actual, `oauth2/`))
+	assert.Equal(t, false, strings.Contains(actual, `sentry/`))
+	assert.Equal(t, true, strings.Contains(actual, `consolecredentials/password`))
+}
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Layer is a set of name,type,value records found under a path
+type Layer struct {
+	Path    string
+	Records [][]string
+}
+
+// ResolvedParameter is the effective value of a key after merging the layers
+type ResolvedParameter struct {
+	Key        string   `json:"key"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Value      string   `json:"value"`
+	Layer      string   `json:"layer"`
+	Overridden []string `json:"overridden,omitempty"`
+}
+
+// ResolveFormats lists the supported resolve output formats
+var ResolveFormats = map[string]bool{
+	"text":     true,
+	"csv":      true,
+	"json":     true,
+	"markdown": true,
+}
+
+// MergeLayers merges the layers, given from the lowest to the highest precedence, by key relative to the layer path.
+// Each key keeps the value of the highest layer defining it and the list of the layers it overrides.
+func MergeLayers(layers []Layer) []ResolvedParameter {
+	resolved := make(map[string]*ResolvedParameter)
+	for _, layer := range layers {
+		prefix := strings.TrimSuffix(layer.Path, "/") + "/"
+		for _, row := range layer.Records {
+			if len(row) < 3 || !strings.HasPrefix(row[0], prefix) {
+				continue
+			}
+			key := strings.TrimPrefix(row[0], prefix)
+			current := &ResolvedParameter{Key: key, Name: row[0], Type: row[1], Value: row[2], Layer: layer.Path}
+			if previous, ok := resolved[key]; ok {
+				current.Overridden = append(append([]string{}, previous.Overridden...), previous.Layer)
+			}
+			resolved[key] = current
+		}
+	}
+
+	var ret []ResolvedParameter
+	for _, param := range resolved {
+		ret = append(ret, *param)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
+}
+
+// WriteResolvedParameters writes the effective parameters in the given format: text, csv, json or markdown.
+// Unless reveal is true SecureString values are masked.
+func WriteResolvedParameters(w io.Writer, format string, params []ResolvedParameter, reveal bool) error {
+	masked := make([]ResolvedParameter, len(params))
+	for i, param := range params {
+		masked[i] = param
+		if !reveal {
+			masked[i].Value = MaskValue(param.Type, param.Value)
+		}
+	}
+
+	switch format {
+	case "", "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+		for _, param := range masked {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", param.Key, param.Type, param.Value, param.Layer)
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"key", "name", "type", "value", "layer", "overridden"})
+		for _, param := range masked {
+			cw.Write([]string{param.Key, param.Name, param.Type, param.Value, param.Layer, strings.Join(param.Overridden, " ")})
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		if masked == nil {
+			masked = []ResolvedParameter{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(masked)
+	case "markdown":
+		lines := []string{
+			"|Key|Type|Value|Layer|Overridden|",
+			"| --- | --- | --- | --- | --- |",
+		}
+		for _, param := range masked {
+			lines = append(lines, fmt.Sprintf("|%s|%s|%s|%s|%s|", markdownCell(param.Key), param.Type, markdownCell(param.Value), markdownCell(param.Layer), markdownCell(strings.Join(param.Overridden, ", "))))
+		}
+		_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+		return err
+	}
+	return fmt.Errorf("unknown format %s", format)
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testLayers = []Layer{
+	{Path: "/prod/common", Records: [][]string{
+		{"/prod/common/redis/endpoint", "String", "redis.prod"},
+		{"/prod/common/log/level", "String", "Warning"},
+	}},
+	{Path: "/prod/dom/common", Records: [][]string{
+		{"/prod/dom/common/redis/endpoint", "String", "redis.dom"},
+		{"/prod/dom/common/db/password", "SecureString", "hunter2"},
+	}},
+	{Path: "/prod/dom/proj", Records: [][]string{
+		{"/prod/dom/proj/redis/endpoint", "String", "redis.proj"},
+		{"/prod/dom/proj/webapp/port", "String", "8080"},
+	}},
+}
+
+func TestMergeLayers(t *testing.T) {
+	resolved := MergeLayers(testLayers)
+
+	assert.Equal(t, []ResolvedParameter{
+		{Key: "db/password", Name: "/prod/dom/common/db/password", Type: "SecureString", Value: "hunter2", Layer: "/prod/dom/common"},
+		{Key: "log/level", Name: "/prod/common/log/level", Type: "String", Value: "Warning", Layer: "/prod/common"},
+		{Key: "redis/endpoint", Name: "/prod/dom/proj/redis/endpoint", Type: "String", Value: "redis.proj", Layer: "/prod/dom/proj", Overridden: []string{"/prod/common", "/prod/dom/common"}},
+		{Key: "webapp/port", Name: "/prod/dom/proj/webapp/port", Type: "String", Value: "8080", Layer: "/prod/dom/proj"},
+	}, resolved)
+}
+
+func TestMergeLayersPrecedence(t *testing.T) {
+	reversed := []Layer{testLayers[2], testLayers[1], testLayers[0]}
+
+	resolved := MergeLayers(reversed)
+
+	assert.Equal(t, "redis.prod", resolved[2].Value)
+	assert.Equal(t, "/prod/common", resolved[2].Layer)
+}
+
+func TestWriteResolvedParametersMasksSecureValues(t *testing.T) {
+	for format := range ResolveFormats {
+		var buffer bytes.Buffer
+		err := WriteResolvedParameters(&buffer, format, MergeLayers(testLayers), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, false, strings.Contains(buffer.String(), "hunter2"), format)
+		assert.Equal(t, true, strings.Contains(buffer.String(), "redis.proj"), format)
+	}
+
+	var buffer bytes.Buffer
+	WriteResolvedParameters(&buffer, "csv", MergeLayers(testLayers), true)
+	assert.Equal(t, true, strings.Contains(buffer.String(), "hunter2"))
+}
@@ -0,0 +1,118 @@
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DocumentFormats lists the formats parameters can be edited in
+var DocumentFormats = map[string]bool{
+	"csv":  true,
+	"yaml": true,
+	"json": true,
+}
+
+// ActionDelete is the action of a parameter removed while editing
+const ActionDelete = "DELETE"
+
+type documentParameter struct {
+	Name  string `yaml:"name" json:"name"`
+	Type  string `yaml:"type" json:"type"`
+	Value string `yaml:"value" json:"value"`
+}
+
+// EncodeParameters serializes name,type,value records in the given format: csv, yaml or json
+func EncodeParameters(format string, records [][]string) ([]byte, error) {
+	if format == "csv" {
+		var buffer bytes.Buffer
+		w := csv.NewWriter(&buffer)
+		w.WriteAll(records)
+		return buffer.Bytes(), w.Error()
+	}
+
+	params := []documentParameter{}
+	for _, row := range records {
+		params = append(params, documentParameter{Name: row[0], Type: row[1], Value: row[2]})
+	}
+	switch format {
+	case "yaml":
+		return yaml.Marshal(params)
+	case "json":
+		data, err := json.MarshalIndent(params, "", "  ")
+		return append(data, '\n'), err
+	}
+	return nil, fmt.Errorf("unknown format %s", format)
+}
+
+// DecodeParameters parses name,type,value records serialized by EncodeParameters
+func DecodeParameters(format string, data []byte) ([][]string, error) {
+	var records [][]string
+	var params []documentParameter
+	var err error
+	switch format {
+	case "csv":
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = 3
+		return r.ReadAll()
+	case "yaml":
+		err = yaml.UnmarshalStrict(data, &params)
+	case "json":
+		err = json.Unmarshal(data, &params)
+	default:
+		err = fmt.Errorf("unknown format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, param := range params {
+		records = append(records, []string{param.Name, param.Type, param.Value})
+	}
+	return records, nil
+}
+
+// DiffRecords classifies the changes from the original to the edited records as validate does:
+// new names are created, changed values overwritten, or destructive for common parameters, and removed names deleted.
+// Unchanged records are not returned.
+func DiffRecords(original [][]string, edited [][]string) []ValidationResult {
+	before := make(map[string][]string)
+	for _, row := range original {
+		before[row[0]] = row
+	}
+	after := make(map[string][]string)
+	for _, row := range edited {
+		after[row[0]] = row
+	}
+
+	var ret []ValidationResult
+	for name, row := range after {
+		old, ok := before[name]
+		result := ValidationResult{Name: name, Type: row[1], NewValue: row[2]}
+		switch {
+		case !ok:
+			result.State, result.Action = StateMissing, ActionCreate
+		case old[1] == row[1] && old[2] == row[2]:
+			continue
+		default:
+			result.State, result.Action = StatePresent, ActionOverwrite
+			result.OldValue = MaskValue(old[1], old[2])
+			if strings.Contains(name, "/common/") {
+				result.Action = ActionDestructive
+				result.Message = "other projects using this common parameter will change too"
+			}
+		}
+		ret = append(ret, result)
+	}
+	for name, old := range before {
+		if _, ok := after[name]; !ok {
+			ret = append(ret, ValidationResult{State: StatePresent, Action: ActionDelete, Name: name, Type: old[1], OldValue: MaskValue(old[1], old[2])})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
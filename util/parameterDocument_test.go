@@ -0,0 +1,61 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testDocumentRecords = [][]string{
+	{"/staging/dom/proj/db/password", "SecureString", "secret, with comma"},
+	{"/staging/dom/proj/webapp/port", "String", "8080"},
+}
+
+func TestEncodeDecodeParameters(t *testing.T) {
+	for format := range DocumentFormats {
+		data, err := EncodeParameters(format, testDocumentRecords)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := DecodeParameters(format, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, testDocumentRecords, records, format)
+	}
+}
+
+func TestDecodeParametersInvalidDocument(t *testing.T) {
+	_, err := DecodeParameters("csv", []byte("/staging/dom/proj/webapp/port,String\n"))
+	assert.NotNil(t, err)
+
+	_, err = DecodeParameters("yaml", []byte("- name: /staging/dom/proj/webapp/port\n  valu: 8080\n"))
+	assert.NotNil(t, err)
+}
+
+func TestDiffRecords(t *testing.T) {
+	original := [][]string{
+		{"/staging/common/redis/endpoint", "String", "redis.old"},
+		{"/staging/dom/proj/db/password", "SecureString", "old-secret"},
+		{"/staging/dom/proj/log/level", "String", "Debug"},
+		{"/staging/dom/proj/webapp/port", "String", "8080"},
+	}
+	edited := [][]string{
+		{"/staging/common/redis/endpoint", "String", "redis.new"},
+		{"/staging/dom/proj/db/password", "SecureString", "new-secret"},
+		{"/staging/dom/proj/webapp/port", "String", "8080"},
+		{"/staging/dom/proj/webapp/host", "String", "0.0.0.0"},
+	}
+
+	results := DiffRecords(original, edited)
+
+	assert.Equal(t, 4, len(results))
+	assert.Equal(t, "PRESENT -> DESTRUCTIVE", results[0].Status())
+	assert.Equal(t, "PRESENT -> OVERWRITE", results[1].Status())
+	assert.Equal(t, MaskedValue, results[1].OldValue)
+	assert.Equal(t, "PRESENT -> DELETE", results[2].Status())
+	assert.Equal(t, "/staging/dom/proj/log/level", results[2].Name)
+	assert.Equal(t, "MISSING -> CREATE", results[3].Status())
+}
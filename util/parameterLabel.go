@@ -0,0 +1,32 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxLabelLength is the maximum length of a parameter version label
+const MaxLabelLength = 100
+
+var validLabel = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+
+// CheckLabel returns an error if the label would be rejected by Parameter Store
+func CheckLabel(label string) error {
+	switch {
+	case label == "" || len(label) > MaxLabelLength:
+		return fmt.Errorf("a label must have 1 to %d characters", MaxLabelLength)
+	case !validLabel.MatchString(label):
+		return fmt.Errorf("label %s contains characters other than letters, numbers, periods, hyphens and underscores", label)
+	case label[0] >= '0' && label[0] <= '9':
+		return fmt.Errorf("label %s can't begin with a number", label)
+	case strings.HasPrefix(strings.ToLower(label), "aws") || strings.HasPrefix(strings.ToLower(label), "ssm"):
+		return fmt.Errorf("label %s can't begin with aws or ssm", label)
+	}
+	return nil
+}
+
+// LabelSelector returns the name:label selector reading the version of a parameter with the label
+func LabelSelector(name string, label string) string {
+	return name + ":" + label
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLabel(t *testing.T) {
+	assert.Nil(t, CheckLabel("release-42"))
+	assert.Nil(t, CheckLabel("stable"))
+	assert.NotNil(t, CheckLabel(""))
+	assert.NotNil(t, CheckLabel("42-release"))
+	assert.NotNil(t, CheckLabel("release 42"))
+	assert.NotNil(t, CheckLabel("AWS-stable"))
+	assert.NotNil(t, CheckLabel("ssm.stable"))
+}
+
+func TestLabelSelector(t *testing.T) {
+	assert.Equal(t, "/prod/dom/proj/webapp/port:release-42", LabelSelector("/prod/dom/proj/webapp/port", "release-42"))
+}
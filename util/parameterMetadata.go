@@ -0,0 +1,127 @@
+package util
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ParameterMetadata describes a parameter without its value
+type ParameterMetadata struct {
+	Name             string
+	Type             string
+	Tier             string
+	Version          int64
+	LastModifiedDate time.Time
+	LastModifiedUser string
+	KeyID            string
+}
+
+// MetadataColumns lists the columns shown by WriteMetadata, in their default order
+var MetadataColumns = []string{"name", "type", "tier", "version", "modified", "user", "key"}
+
+// ListFormats lists the supported metadata output formats
+var ListFormats = map[string]bool{
+	"text": true,
+	"csv":  true,
+}
+
+// Column returns the value of a metadata column as text
+func (m ParameterMetadata) Column(column string) string {
+	switch column {
+	case "name":
+		return m.Name
+	case "type":
+		return m.Type
+	case "tier":
+		return m.Tier
+	case "version":
+		return strconv.FormatInt(m.Version, 10)
+	case "modified":
+		if m.LastModifiedDate.IsZero() {
+			return ""
+		}
+		return m.LastModifiedDate.UTC().Format(time.RFC3339)
+	case "user":
+		return m.LastModifiedUser
+	case "key":
+		return m.KeyID
+	}
+	return ""
+}
+
+// CheckColumns returns an error if one of the columns is unknown
+func CheckColumns(columns []string) error {
+	known := make(map[string]bool)
+	for _, column := range MetadataColumns {
+		known[column] = true
+	}
+	for _, column := range columns {
+		if !known[column] {
+			return fmt.Errorf("unknown column %s, use one of %s", column, strings.Join(MetadataColumns, ","))
+		}
+	}
+	return nil
+}
+
+// SortMetadata sorts the parameters by a column, versions and dates are compared by value and ties are sorted by name
+func SortMetadata(list []ParameterMetadata, column string, reverse bool) {
+	less := func(a ParameterMetadata, b ParameterMetadata) bool {
+		switch column {
+		case "version":
+			if a.Version != b.Version {
+				return a.Version < b.Version
+			}
+		case "modified":
+			if !a.LastModifiedDate.Equal(b.LastModifiedDate) {
+				return a.LastModifiedDate.Before(b.LastModifiedDate)
+			}
+		default:
+			if a.Column(column) != b.Column(column) {
+				return a.Column(column) < b.Column(column)
+			}
+		}
+		return a.Name < b.Name
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if reverse {
+			return less(list[j], list[i])
+		}
+		return less(list[i], list[j])
+	})
+}
+
+// WriteMetadata writes the selected columns of the parameters in the given format: text or csv
+func WriteMetadata(w io.Writer, format string, list []ParameterMetadata, columns []string) error {
+	switch format {
+	case "", "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, m := range list {
+			fmt.Fprintln(tw, strings.Join(m.columns(columns), "\t"))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(columns)
+		for _, m := range list {
+			cw.Write(m.columns(columns))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown format %s", format)
+}
+
+func (m ParameterMetadata) columns(columns []string) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = m.Column(column)
+	}
+	return values
+}
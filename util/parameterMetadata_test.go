@@ -0,0 +1,60 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMetadata() []ParameterMetadata {
+	return []ParameterMetadata{
+		{Name: "/prod/dom/proj/webapp/port", Type: "String", Tier: "Standard", Version: 12, LastModifiedDate: time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC), LastModifiedUser: "arn:aws:iam::123456789012:user/alice"},
+		{Name: "/prod/dom/proj/db/password", Type: "SecureString", Tier: "Standard", Version: 3, LastModifiedDate: time.Date(2021, 5, 4, 8, 30, 0, 0, time.UTC), KeyID: "alias/aws/ssm"},
+		{Name: "/prod/dom/proj/log/level", Type: "String", Tier: "Standard", Version: 3, LastModifiedDate: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestSortMetadata(t *testing.T) {
+	list := newTestMetadata()
+
+	SortMetadata(list, "version", false)
+	assert.Equal(t, "/prod/dom/proj/db/password", list[0].Name)
+	assert.Equal(t, "/prod/dom/proj/log/level", list[1].Name)
+	assert.Equal(t, "/prod/dom/proj/webapp/port", list[2].Name)
+
+	SortMetadata(list, "modified", true)
+	assert.Equal(t, "/prod/dom/proj/db/password", list[0].Name)
+	assert.Equal(t, "/prod/dom/proj/log/level", list[2].Name)
+}
+
+func TestWriteMetadata(t *testing.T) {
+	list := newTestMetadata()
+	SortMetadata(list, "name", false)
+
+	var buffer bytes.Buffer
+	err := WriteMetadata(&buffer, "text", list, []string{"name", "version", "modified"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `NAME                        VERSION  MODIFIED
+/prod/dom/proj/db/password  3        2021-05-04T08:30:00Z
+/prod/dom/proj/log/level    3        2019-01-01T00:00:00Z
+/prod/dom/proj/webapp/port  12       2020-03-01T10:00:00Z
+`
+	assert.Equal(t, expected, buffer.String())
+
+	buffer.Reset()
+	err = WriteMetadata(&buffer, "csv", list[:1], []string{"name", "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "name,key\n/prod/dom/proj/db/password,alias/aws/ssm\n", buffer.String())
+}
+
+func TestCheckColumns(t *testing.T) {
+	assert.Nil(t, CheckColumns([]string{"name", "user"}))
+	assert.NotNil(t, CheckColumns([]string{"name", "value"}))
+}
@@ -0,0 +1,196 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ParameterNode is a segment of the parameter hierarchy, it is a parameter when Type is set
+// and it can have children at the same time
+type ParameterNode struct {
+	Name     string
+	Path     string
+	Type     string
+	Value    string
+	Loaded   bool
+	Expanded bool
+	Children []*ParameterNode
+}
+
+// TreeRow is a node shown by a tree view with its depth
+type TreeRow struct {
+	Depth int
+	Node  *ParameterNode
+}
+
+// NewParameterTree builds the hierarchy below root from a map of parameter names and types, values are loaded later
+func NewParameterTree(root string, types map[string]string) *ParameterNode {
+	tree := &ParameterNode{Name: root, Path: strings.TrimSuffix(root, "/"), Expanded: true}
+	for name, paramType := range types {
+		tree.Add(name, paramType)
+	}
+	return tree
+}
+
+// Add inserts a parameter creating the missing intermediate segments
+func (n *ParameterNode) Add(name string, paramType string) *ParameterNode {
+	relative := strings.TrimPrefix(name, n.Path+"/")
+	if relative == name && n.Path != "" {
+		return nil
+	}
+	node := n
+	for _, segment := range strings.Split(strings.Trim(relative, "/"), "/") {
+		node = node.child(segment)
+	}
+	node.Type = paramType
+	return node
+}
+
+func (n *ParameterNode) child(segment string) *ParameterNode {
+	i := sort.Search(len(n.Children), func(i int) bool { return n.Children[i].Name >= segment })
+	if i < len(n.Children) && n.Children[i].Name == segment {
+		return n.Children[i]
+	}
+	child := &ParameterNode{Name: segment, Path: n.Path + "/" + segment}
+	n.Children = append(n.Children, nil)
+	copy(n.Children[i+1:], n.Children[i:])
+	n.Children[i] = child
+	return child
+}
+
+// Find returns the node with the given path, nil if it doesn't exist
+func (n *ParameterNode) Find(path string) *ParameterNode {
+	if path == n.Path {
+		return n
+	}
+	for _, child := range n.Children {
+		if path == child.Path || strings.HasPrefix(path, child.Path+"/") {
+			return child.Find(path)
+		}
+	}
+	return nil
+}
+
+// Remove deletes the parameter with the given path, dropping the segments left without parameters
+func (n *ParameterNode) Remove(path string) {
+	for i, child := range n.Children {
+		if path == child.Path {
+			child.Type, child.Value, child.Loaded = "", "", false
+		} else if strings.HasPrefix(path, child.Path+"/") {
+			child.Remove(path)
+		} else {
+			continue
+		}
+		if child.Type == "" && len(child.Children) == 0 {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+		}
+		return
+	}
+}
+
+// IsParameter returns true if the node is a parameter
+func (n *ParameterNode) IsParameter() bool {
+	return n.Type != ""
+}
+
+// Count returns the number of parameters below the node, the node itself excluded
+func (n *ParameterNode) Count() int {
+	count := 0
+	for _, child := range n.Children {
+		if child.IsParameter() {
+			count++
+		}
+		count += child.Count()
+	}
+	return count
+}
+
+// Rows returns the visible nodes below the node in display order.
+// With a filter only the parameters whose path contains it, case insensitive, and their ancestors are returned, all expanded.
+func (n *ParameterNode) Rows(filter string) []TreeRow {
+	return n.rows(0, strings.ToLower(filter))
+}
+
+func (n *ParameterNode) rows(depth int, filter string) []TreeRow {
+	var ret []TreeRow
+	for _, child := range n.Children {
+		below := []TreeRow{}
+		if child.Expanded || filter != "" {
+			below = child.rows(depth+1, filter)
+		}
+		if filter != "" && len(below) == 0 && !(child.IsParameter() && strings.Contains(strings.ToLower(child.Path), filter)) {
+			continue
+		}
+		ret = append(ret, TreeRow{Depth: depth, Node: child})
+		ret = append(ret, below...)
+	}
+	return ret
+}
+
+// TreeMarkers are the short type markers shown by WriteTree
+var TreeMarkers = map[string]string{
+	"String":       "[S]",
+	"StringList":   "[L]",
+	"SecureString": "[SS]",
+}
+
+// TreeOptions controls the rendering of WriteTree: Depth limits the levels shown (0 shows all),
+// Width truncates the lines (0 doesn't truncate) and Reveal shows the SecureString values
+type TreeOptions struct {
+	Depth  int
+	Width  int
+	Reveal bool
+}
+
+// WriteTree renders the hierarchy like the tree command, with the number of parameters of every subtree
+// and a preview of the parameter values
+func WriteTree(w io.Writer, tree *ParameterNode, options TreeOptions) error {
+	root := tree.Name
+	if root == "" {
+		root = "/"
+	}
+	if _, err := fmt.Fprintln(w, TruncateLine(fmt.Sprintf("%s (%d)", root, tree.Count()), options.Width)); err != nil {
+		return err
+	}
+	return writeTreeChildren(w, tree, "", 1, options)
+}
+
+func writeTreeChildren(w io.Writer, node *ParameterNode, indent string, depth int, options TreeOptions) error {
+	for i, child := range node.Children {
+		branch, next := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, next = "└── ", "    "
+		}
+		line := indent + branch + child.Name
+		if len(child.Children) > 0 {
+			line += fmt.Sprintf("/ (%d)", child.Count())
+		}
+		if child.IsParameter() {
+			preview := child.Value
+			if !options.Reveal {
+				preview = MaskValue(child.Type, preview)
+			}
+			line += " " + TreeMarkers[child.Type] + " = " + strings.Replace(preview, "\n", " ", -1)
+		}
+		if _, err := fmt.Fprintln(w, TruncateLine(line, options.Width)); err != nil {
+			return err
+		}
+		if options.Depth == 0 || depth < options.Depth {
+			if err := writeTreeChildren(w, child, indent+next, depth+1, options); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TruncateLine cuts the line to width runes marking the cut with an ellipsis, a width of 0 doesn't truncate
+func TruncateLine(line string, width int) string {
+	runes := []rune(line)
+	if width > 1 && len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return line
+}
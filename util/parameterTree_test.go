@@ -0,0 +1,102 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestParameterTree() *ParameterNode {
+	return NewParameterTree("/staging", map[string]string{
+		"/staging/dom/proj/webapp/port":   "String",
+		"/staging/dom/proj/db/password":   "SecureString",
+		"/staging/dom/proj/db":            "String",
+		"/staging/common/redis/endpoint":  "String",
+		"/production/dom/proj/webapp/url": "String",
+	})
+}
+
+func rowPaths(rows []TreeRow) []string {
+	var paths []string
+	for _, row := range rows {
+		paths = append(paths, row.Node.Path)
+	}
+	return paths
+}
+
+func TestParameterTree(t *testing.T) {
+	tree := newTestParameterTree()
+
+	assert.Equal(t, 4, tree.Count())
+	assert.Equal(t, []string{"/staging/common", "/staging/dom"}, rowPaths(tree.Rows("")))
+
+	db := tree.Find("/staging/dom/proj/db")
+	assert.Equal(t, true, db.IsParameter())
+	assert.Equal(t, 1, len(db.Children))
+	assert.Nil(t, tree.Find("/production/dom/proj/webapp/url"))
+
+	tree.Find("/staging/dom").Expanded = true
+	rows := tree.Rows("")
+	assert.Equal(t, []string{"/staging/common", "/staging/dom", "/staging/dom/proj"}, rowPaths(rows))
+	assert.Equal(t, 1, rows[2].Depth)
+}
+
+func TestParameterTreeFilter(t *testing.T) {
+	tree := newTestParameterTree()
+
+	rows := tree.Rows("PASS")
+
+	assert.Equal(t, []string{"/staging/dom", "/staging/dom/proj", "/staging/dom/proj/db", "/staging/dom/proj/db/password"}, rowPaths(rows))
+	assert.Equal(t, 3, rows[3].Depth)
+}
+
+func TestParameterTreeRemove(t *testing.T) {
+	tree := newTestParameterTree()
+
+	tree.Remove("/staging/common/redis/endpoint")
+	tree.Remove("/staging/dom/proj/db/password")
+
+	assert.Nil(t, tree.Find("/staging/common"))
+	assert.Equal(t, 0, len(tree.Find("/staging/dom/proj/db").Children))
+	assert.Equal(t, 2, tree.Count())
+}
+
+func TestWriteTree(t *testing.T) {
+	tree := newTestParameterTree()
+	tree.Find("/staging/dom/proj/db/password").Value = "secret"
+	tree.Find("/staging/dom/proj/db").Value = "postgres"
+	tree.Find("/staging/dom/proj/webapp/port").Value = "8080"
+	tree.Find("/staging/common/redis/endpoint").Value = "redis.staging.internal"
+
+	var buffer bytes.Buffer
+	err := WriteTree(&buffer, tree, TreeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `/staging (4)
+├── common/ (1)
+│   └── redis/ (1)
+│       └── endpoint [S] = redis.staging.internal
+└── dom/ (3)
+    └── proj/ (3)
+        ├── db/ (1) [S] = postgres
+        │   └── password [SS] = ********
+        └── webapp/ (1)
+            └── port [S] = 8080
+`
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestWriteTreeDepthAndWidth(t *testing.T) {
+	tree := newTestParameterTree()
+
+	var buffer bytes.Buffer
+	err := WriteTree(&buffer, tree, TreeOptions{Depth: 1, Width: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "/staging (4)\n├── common/…\n└── dom/ (3)\n", buffer.String())
+}
@@ -0,0 +1,134 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var propertiesIndex = regexp.MustCompile(`\[(\d+)\]`)
+
+type propertiesToCsvConverter struct {
+	flattener
+}
+
+// NewPropertiesToCsvConverter create a new converter for Java .properties files,
+// dotted keys and list indexes such as hosts[0] become key segments
+func NewPropertiesToCsvConverter(options ...ConverterOption) converter {
+	return &propertiesToCsvConverter{newFlattener(options)}
+}
+
+// Convert return a slice of strings with converted data
+func (c *propertiesToCsvConverter) Convert(inputProperties []byte) ([]string, error) {
+	rows, err := c.ConvertWithValues(inputProperties)
+	return keysOf(rows), err
+}
+
+// ConvertWithValues return a slice of keys with their default values
+func (c *propertiesToCsvConverter) ConvertWithValues(inputProperties []byte) ([]KeyValue, error) {
+	var ret []KeyValue
+	lines, err := logicalPropertiesLines(inputProperties)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		key, value, err := splitPropertiesLine(line)
+		if err != nil {
+			return nil, err
+		}
+		key = propertiesIndex.ReplaceAllString(key, ".$1")
+		ret = append(ret, c.getValueRows(strings.Split(key, "."), value)...)
+	}
+	return ret, nil
+}
+
+// logicalPropertiesLines joins continuation lines and drops blanks and comments
+func logicalPropertiesLines(input []byte) ([]string, error) {
+	var ret []string
+	var current string
+	continued := false
+	scanner := bufio.NewScanner(bytes.NewReader(input))
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if !continued && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		trailing := len(line) - len(strings.TrimRight(line, "\\"))
+		continued = trailing%2 == 1
+		if continued {
+			line = line[:len(line)-1]
+		}
+		current += line
+		if !continued {
+			ret = append(ret, current)
+			current = ""
+		}
+	}
+	if current != "" {
+		ret = append(ret, current)
+	}
+	return ret, scanner.Err()
+}
+
+func splitPropertiesLine(line string) (key string, value string, err error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '=' || line[i] == ':' || line[i] == ' ' || line[i] == '\t' || line[i] == '\f' {
+			end = i
+			break
+		}
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	if key, err = unescapeProperties(line[:end]); err != nil {
+		return "", "", err
+	}
+	value, err = unescapeProperties(rest)
+	return key, value, err
+}
+
+func unescapeProperties(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
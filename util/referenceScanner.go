@@ -0,0 +1,161 @@
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// ScannedExtensions lists the extensions of the source and config files searched for parameter references
+var ScannedExtensions = map[string]bool{
+	".go": true, ".js": true, ".ts": true, ".py": true, ".java": true, ".cs": true, ".rb": true, ".php": true, ".sh": true,
+	".json": true, ".yaml": true, ".yml": true, ".toml": true, ".properties": true, ".env": true, ".tf": true, ".tfvars": true,
+}
+
+// SkippedDirs lists the directories not scanned
+var SkippedDirs = map[string]bool{
+	".git":         true,
+	".terraform":   true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// referencePatterns match the parameter names referenced by CloudFormation dynamic references,
+// Serverless variables and Terraform aws_ssm_parameter blocks, the name is the first group
+var referencePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\{\{resolve:ssm(?:-secure)?:([^:}]+)`),
+	regexp.MustCompile(`\$\{ssm(?:\([^)]*\))?:([^}~]+)`),
+	regexp.MustCompile(`"aws_ssm_parameter"\s+"[^"]+"\s*\{[^}]*?\bname\s*=\s*"([^"]+)"`),
+}
+
+// Reference is a parameter name found in a file
+type Reference struct {
+	Name string
+	File string
+	Line int
+}
+
+// Location returns the file and line of the reference, e.g. main.go:12
+func (r Reference) Location() string {
+	return r.File + ":" + strconv.Itoa(r.Line)
+}
+
+// FindReferences returns the parameter names under prefix referenced in data, either by the known
+// CloudFormation, Serverless and Terraform syntaxes or as plain strings, e.g. in Go, JSON or YAML.
+// The prefix alone is not a reference: it is usually the start of names built in code, e.g. "/prod/dom/" + project.
+func FindReferences(file string, data []byte, prefix string) []Reference {
+	prefix = strings.TrimSuffix(prefix, "/")
+	// a plain string must end where the name does, so that /prod/dom doesn't match /prod/domain
+	patterns := append(referencePatterns, regexp.MustCompile(`(`+regexp.QuoteMeta(prefix)+`(?:/[A-Za-z0-9_.\-]+)*)(?:[^A-Za-z0-9_.\-]|$)`))
+
+	found := make(map[string]bool)
+	var ret []Reference
+	for _, pattern := range patterns {
+		for _, match := range pattern.FindAllSubmatchIndex(data, -1) {
+			start, end := match[2], match[3]
+			name := strings.TrimSpace(string(data[start:end]))
+			if !strings.HasPrefix(name, prefix+"/") {
+				continue
+			}
+			line := bytes.Count(data[:start], []byte("\n")) + 1
+			key := name + ":" + strconv.Itoa(line)
+			if !found[key] {
+				found[key] = true
+				ret = append(ret, Reference{Name: name, File: file, Line: line})
+			}
+		}
+	}
+	return ret
+}
+
+// ScanDir searches the source and config files under dir for references to parameters under prefix
+func ScanDir(dir string, prefix string) ([]Reference, error) {
+	var ret []Reference
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if SkippedDirs[info.Name()] && file != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !ScannedExtensions[strings.ToLower(filepath.Ext(file))] && !strings.HasPrefix(info.Name(), ".env") {
+			return nil
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		ret = append(ret, FindReferences(file, data, prefix)...)
+		return nil
+	})
+	return ret, err
+}
+
+// CompareReferences returns the references to names missing from the store and the stored names never referenced.
+// A reference to a path uses every parameter below it, as code reading the parameters by path does.
+func CompareReferences(references []Reference, names []string) (missing []Reference, unused []string) {
+	used := make(map[string]bool)
+	for _, reference := range references {
+		exists := false
+		for _, name := range names {
+			if name == reference.Name || strings.HasPrefix(name, reference.Name+"/") {
+				used[name] = true
+				exists = true
+			}
+		}
+		if !exists {
+			missing = append(missing, reference)
+		}
+	}
+	for _, name := range names {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Name != missing[j].Name {
+			return missing[i].Name < missing[j].Name
+		}
+		return missing[i].Location() < missing[j].Location()
+	})
+	sort.Strings(unused)
+	return missing, unused
+}
+
+// WriteScanReport writes the missing and unused parameters in the given format: text or csv
+func WriteScanReport(w io.Writer, format string, missing []Reference, unused []string) error {
+	rows := [][]string{}
+	for _, reference := range missing {
+		rows = append(rows, []string{"MISSING", reference.Name, reference.Location()})
+	}
+	for _, name := range unused {
+		rows = append(rows, []string{"UNUSED", name, ""})
+	}
+
+	switch format {
+	case "", "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.TrimRight(strings.Join(row, "\t"), "\t"))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"status", "name", "location"})
+		cw.WriteAll(rows)
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown format %s", format)
+}
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func referenceNames(references []Reference) []string {
+	var names []string
+	for _, reference := range references {
+		names = append(names, reference.Name)
+	}
+	return names
+}
+
+func TestFindReferences(t *testing.T) {
+	data := []byte(`QueueName: "{{resolve:ssm:/prod/dom/proj/queue/name:3}}"
+LOG_LEVEL: ${ssm(eu-west-1):/prod/dom/proj/log/level~true}
+OTHER: ${ssm:/staging/dom/proj/log/level}
+`)
+
+	references := FindReferences("serverless.yml", data, "/prod/dom/")
+
+	assert.Equal(t, []string{"/prod/dom/proj/queue/name", "/prod/dom/proj/log/level"}, referenceNames(references))
+	assert.Equal(t, "serverless.yml:2", references[1].Location())
+}
+
+func TestFindReferencesSiblingPrefix(t *testing.T) {
+	data := []byte(`const queue = "/prod/domain/proj/queue/name"
+const level = "/prod/dom/proj/log/level"
+`)
+
+	references := FindReferences("main.go", data, "/prod/dom")
+
+	assert.Equal(t, []string{"/prod/dom/proj/log/level"}, referenceNames(references))
+}
+
+func TestPrefixIsNotAReference(t *testing.T) {
+	data := []byte(`name := "/prod/dom/" + project + "/db/password"
+`)
+
+	references := FindReferences("main.go", data, "/prod/dom")
+	assert.Equal(t, 0, len(references))
+
+	_, unused := CompareReferences(references, []string{"/prod/dom/proj/db/password"})
+	assert.Equal(t, []string{"/prod/dom/proj/db/password"}, unused)
+}
+
+func TestFindTerraformReferences(t *testing.T) {
+	data := []byte("data \"aws_ssm_parameter\" \"endpoint\" {\n  name = \"dom/proj/redis/endpoint\"\n}\n")
+
+	references := FindReferences("main.tf", data, "dom")
+
+	assert.Equal(t, []string{"dom/proj/redis/endpoint"}, referenceNames(references))
+	assert.Equal(t, 2, references[0].Line)
+}
+
+func TestScanDir(t *testing.T) {
+	references, err := ScanDir("scan", "/prod/dom")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missing, unused := CompareReferences(references, []string{
+		"/prod/dom/proj/db/password",
+		"/prod/dom/proj/webapp/host",
+		"/prod/dom/proj/webapp/port",
+		"/prod/dom/proj/log/level",
+		"/prod/dom/proj/queue/name",
+		"/prod/dom/proj/queue/secret",
+		"/prod/dom/proj/old/flag",
+	})
+
+	assert.Equal(t, []string{"/prod/dom/proj/redis/endpoint", "/prod/dom/proj/region/key"}, referenceNames(missing))
+	assert.Equal(t, filepath.Join("scan", "main.tf")+":2", missing[0].Location())
+	assert.Equal(t, []string{"/prod/dom/proj/old/flag"}, unused)
+
+	var buffer bytes.Buffer
+	err = WriteScanReport(&buffer, "csv", missing[1:], unused)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "status,name,location\nMISSING,/prod/dom/proj/region/key,scan/serverless.yml:4\nUNUSED,/prod/dom/proj/old/flag,\n", buffer.String())
+}
@@ -0,0 +1,3 @@
+data "aws_ssm_parameter" "endpoint" {
+  name = "/prod/dom/proj/redis/endpoint"
+}
@@ -0,0 +1,12 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import "os"
+
+func main() {
+	password := os.Getenv("/prod/dom/proj/db/password")
+	all := os.Getenv("/prod/dom/proj/webapp")
+	println(password, all)
+}
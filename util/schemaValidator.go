@@ -0,0 +1,162 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Schema defines the rules a project parameter set must satisfy
+type Schema struct {
+	Parameters []SchemaRule `yaml:"parameters" json:"parameters"`
+}
+
+// SchemaRule defines the constraints of a single parameter.
+// Name is matched against the trailing segments of the parameter name and can contain glob patterns.
+type SchemaRule struct {
+	Name     string   `yaml:"name" json:"name"`
+	Required bool     `yaml:"required" json:"required"`
+	Type     string   `yaml:"type" json:"type"`
+	Pattern  string   `yaml:"pattern" json:"pattern"`
+	Format   string   `yaml:"format" json:"format"`
+	Allowed  []string `yaml:"allowed" json:"allowed"`
+
+	regex *regexp.Regexp
+}
+
+// Violation describes a broken rule, Row is the 1-based row of the offending record or 0 if the record is missing
+type Violation struct {
+	Row     int
+	Name    string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Row == 0 {
+		return fmt.Sprintf("%s: %s", v.Name, v.Message)
+	}
+	return fmt.Sprintf("row %d %s: %s", v.Row, v.Name, v.Message)
+}
+
+// NewSchema parses a YAML or JSON schema definition
+func NewSchema(data []byte) (*Schema, error) {
+	schema := &Schema{}
+	err := yaml.Unmarshal(data, schema)
+	if err != nil {
+		return nil, err
+	}
+	for i := range schema.Parameters {
+		rule := &schema.Parameters[i]
+		if rule.Name == "" {
+			return nil, fmt.Errorf("schema rule %d has no name", i+1)
+		}
+		if _, err := path.Match(rule.Name, ""); err != nil {
+			return nil, fmt.Errorf("schema rule %s: %v", rule.Name, err)
+		}
+		switch rule.Format {
+		case "", "int", "number", "bool", "url":
+		default:
+			return nil, fmt.Errorf("schema rule %s: unknown format %s", rule.Name, rule.Format)
+		}
+		if rule.Pattern != "" {
+			rule.regex, err = regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("schema rule %s: %v", rule.Name, err)
+			}
+		}
+	}
+	return schema, nil
+}
+
+// Validate checks name,type,value records against the schema and returns every violation found
+func (s *Schema) Validate(records [][]string) []Violation {
+	var ret []Violation
+	found := make([]bool, len(s.Parameters))
+
+	for i, row := range records {
+		if len(row) < 3 {
+			ret = append(ret, Violation{Row: i + 1, Name: strings.Join(row, ","), Message: "expected 3 columns: name,type,value"})
+			continue
+		}
+		for j, rule := range s.Parameters {
+			if !rule.matches(row[0]) {
+				continue
+			}
+			found[j] = true
+			for _, message := range rule.check(row[1], row[2]) {
+				ret = append(ret, Violation{Row: i + 1, Name: row[0], Message: message})
+			}
+		}
+	}
+
+	for j, rule := range s.Parameters {
+		if rule.Required && !found[j] {
+			ret = append(ret, Violation{Name: rule.Name, Message: "required parameter is missing"})
+		}
+	}
+	return ret
+}
+
+func (r *SchemaRule) matches(name string) bool {
+	ruleSegments := strings.Split(strings.Trim(r.Name, "/"), "/")
+	nameSegments := strings.Split(strings.Trim(name, "/"), "/")
+	if strings.HasPrefix(r.Name, "/") && len(ruleSegments) != len(nameSegments) {
+		return false
+	}
+	if len(nameSegments) < len(ruleSegments) {
+		return false
+	}
+	tail := strings.Join(nameSegments[len(nameSegments)-len(ruleSegments):], "/")
+	matched, _ := path.Match(strings.Join(ruleSegments, "/"), tail)
+	return matched
+}
+
+func (r *SchemaRule) check(paramType string, value string) []string {
+	var ret []string
+	if r.Type != "" && r.Type != paramType {
+		ret = append(ret, fmt.Sprintf("type is %s, expected %s", paramType, r.Type))
+	}
+	if r.regex != nil && !r.regex.MatchString(value) {
+		ret = append(ret, fmt.Sprintf("value does not match pattern %s", r.Pattern))
+	}
+	if r.Format != "" && !validFormat(r.Format, value) {
+		ret = append(ret, fmt.Sprintf("value is not a valid %s", r.Format))
+	}
+	if len(r.Allowed) > 0 && !contains(r.Allowed, value) {
+		ret = append(ret, fmt.Sprintf("value is not one of %s", strings.Join(r.Allowed, ", ")))
+	}
+	return ret
+}
+
+func validFormat(format string, value string) bool {
+	var err error
+	switch format {
+	case "int":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "url":
+		var u *url.URL
+		u, err = url.Parse(value)
+		if err == nil && (u.Scheme == "" || u.Host == "") {
+			return false
+		}
+	}
+	return err == nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readTestCsv(t *testing.T, inputPath string) [][]string {
+	file, err := os.Open(inputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestSchemaReportsEveryViolation(t *testing.T) {
+	file, _ := ioutil.ReadFile("schema/testSchema.yaml")
+
+	schema, err := NewSchema(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations := schema.Validate(readTestCsv(t, "schema/testSchema.csv"))
+
+	var actual []string
+	for _, violation := range violations {
+		actual = append(actual, violation.String())
+	}
+	joined := strings.Join(actual, "|")
+
+	assert.Equal(t, 6, len(violations))
+	assert.Equal(t, true, strings.Contains(joined, "row 1 /dev/dom/proj/sentry/sentrydsn: type is String, expected SecureString"))
+	assert.Equal(t, true, strings.Contains(joined, "row 1 /dev/dom/proj/sentry/sentrydsn: value is not a valid url"))
+	assert.Equal(t, true, strings.Contains(joined, "row 2 /dev/dom/proj/webapp/port: value is not a valid int"))
+	assert.Equal(t, true, strings.Contains(joined, "row 3 /dev/dom/proj/log/level: value is not one of"))
+	assert.Equal(t, true, strings.Contains(joined, "row 4 /dev/dom/proj/redis/endpoint: value does not match pattern"))
+	assert.Equal(t, true, strings.Contains(joined, "oauth2/clientsecret: required parameter is missing"))
+}
+
+func TestSchemaFromJSON(t *testing.T) {
+	file, _ := ioutil.ReadFile("schema/testSchema.json")
+
+	schema, err := NewSchema(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations := schema.Validate([][]string{
+		{"/prod/dom/proj/webapp/port", "String", "8080"},
+		{"/prod/dom/proj/sentry/sentrydsn", "SecureString", "https://key@sentry.io/1"},
+		{"/prod/other/proj/sentry/sentrydsn", "String", "https://key@sentry.io/1"},
+	})
+
+	assert.Equal(t, 0, len(violations))
+}
+
+func TestSchemaShortRowIsAViolation(t *testing.T) {
+	schema, _ := NewSchema([]byte(`parameters: []`))
+
+	violations := schema.Validate([][]string{{"/prod/dom/proj/webapp/port", "String"}})
+
+	assert.Equal(t, 1, len(violations))
+	assert.Equal(t, 1, violations[0].Row)
+}
+
+func TestSchemaInvalidPatternShouldReturnError(t *testing.T) {
+	_, err := NewSchema([]byte(`parameters: [{name: webapp/port, pattern: "[0-9"}]`))
+
+	assert.NotNil(t, err)
+}
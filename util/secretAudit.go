@@ -0,0 +1,152 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultKeyID is the alias of the AWS managed key used by SecureString parameters without a customer managed key
+const DefaultKeyID = "alias/aws/ssm"
+
+// Thresholds of the entropy detector
+const (
+	MinSecretEntropy = 4.0
+	MinSecretLength  = 20
+	MinWeakLength    = 12
+)
+
+// secretPatterns recognize well known credential formats
+var secretPatterns = map[string]*regexp.Regexp{
+	"an AWS access key id":              regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+	"a private key":                     regexp.MustCompile(`-----BEGIN ([A-Z]+ )*PRIVATE KEY-----`),
+	"a JSON web token":                  regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	"a GitHub token":                    regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`),
+	"a Slack token":                     regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	"a password in a connection string": regexp.MustCompile(`://[^/\s:@]+:[^@\s]+@`),
+}
+
+var tokenLike = regexp.MustCompile(`^[A-Za-z0-9+/=_\-]+$`)
+
+// commonPasswords are rejected as secret values
+var commonPasswords = map[string]bool{
+	"password": true, "passw0rd": true, "changeme": true, "admin": true, "root": true, "secret": true,
+	"default": true, "letmein": true, "qwerty": true, "123456": true, "12345678": true, "test": true,
+}
+
+// AuditParameter is a parameter checked by AuditParameters, Row is the CSV row or 0 for the parameter store
+// and KeyID the KMS key of SecureString parameters, when known
+type AuditParameter struct {
+	Row   int
+	Name  string
+	Type  string
+	Value string
+	KeyID string
+}
+
+// Entropy returns the Shannon entropy of the value in bits per character
+func Entropy(value string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// DetectSecret returns why the value looks like a secret, or an empty string
+func DetectSecret(name string, value string) string {
+	if value == "" || strings.Contains(value, "/common/") {
+		return ""
+	}
+	names := make([]string, 0, len(secretPatterns))
+	for detector := range secretPatterns {
+		names = append(names, detector)
+	}
+	sort.Strings(names)
+	for _, detector := range names {
+		if secretPatterns[detector].MatchString(value) {
+			return "looks like " + detector
+		}
+	}
+	if len(value) >= MinSecretLength && tokenLike.MatchString(value) && Entropy(value) >= MinSecretEntropy {
+		return fmt.Sprintf("high entropy value (%.1f bits per character)", Entropy(value))
+	}
+	if NewSecureKeyRules(DefaultSecureKeyPatterns).IsSecure(name) {
+		return "name looks like a secret"
+	}
+	return ""
+}
+
+// AuditParameters checks parameters for secrets stored in plaintext, weak secrets, SecureStrings of the cmkEnvs
+// environments encrypted with the AWS managed key and secrets with the same value in more than one environment.
+// envIndex is the hierarchy level holding the environment, see EnvironmentIndex
+func AuditParameters(params []AuditParameter, cmkEnvs []string, envIndex int) []Violation {
+	var ret []Violation
+	requireCMK := make(map[string]bool)
+	for _, env := range cmkEnvs {
+		requireCMK[env] = true
+	}
+	secrets := make(map[string][]AuditParameter)
+
+	for _, param := range params {
+		add := func(message string) {
+			ret = append(ret, Violation{Row: param.Row, Name: param.Name, Message: message})
+		}
+		secure := param.Type == "SecureString"
+		if reason := DetectSecret(param.Name, param.Value); reason != "" && !secure {
+			add(param.Type + " " + reason + ", use SecureString")
+			secure = true
+		}
+		if !secure || param.Value == "" || strings.Contains(param.Value, "/common/") {
+			continue
+		}
+		if commonPasswords[strings.ToLower(param.Value)] {
+			add("weak secret, it is a common password")
+		} else if len(param.Value) < MinWeakLength {
+			add(fmt.Sprintf("weak secret, shorter than %d characters", MinWeakLength))
+		}
+		if param.Type == "SecureString" && requireCMK[EnvironmentOf(param.Name, envIndex)] && strings.HasSuffix(param.KeyID, DefaultKeyID) {
+			add("encrypted with the AWS managed key, a customer managed key is required in " + EnvironmentOf(param.Name, envIndex))
+		}
+		secrets[param.Value] = append(secrets[param.Value], param)
+	}
+
+	for _, same := range secrets {
+		for _, param := range same {
+			for _, other := range same {
+				if EnvironmentOf(other.Name, envIndex) != EnvironmentOf(param.Name, envIndex) {
+					ret = append(ret, Violation{Row: param.Row, Name: param.Name, Message: "same secret as " + other.Name})
+					break
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Row != ret[j].Row {
+			return ret[i].Row < ret[j].Row
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// AuditRecords runs AuditParameters on name,type,value CSV records, rows with less than 3 columns are skipped
+func AuditRecords(records [][]string, envIndex int) []Violation {
+	var params []AuditParameter
+	for i, row := range records {
+		if len(row) >= 3 {
+			params = append(params, AuditParameter{Row: i + 1, Name: row[0], Type: row[1], Value: row[2]})
+		}
+	}
+	return AuditParameters(params, nil, envIndex)
+}
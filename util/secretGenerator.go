@@ -0,0 +1,76 @@
+package util
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// GeneratePlaceholder is the CSV value replaced with a random secret at upload, e.g. !generate:32 or !generate:32:hex
+const GeneratePlaceholder = "!generate"
+
+// DefaultSecretLength is the length of generated secrets when none is given
+const DefaultSecretLength = 32
+
+// Charsets are the named character sets of generated secrets, any other charset is used as the list of characters
+var Charsets = map[string]string{
+	"alnum":   "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	"alpha":   "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"digits":  "0123456789",
+	"hex":     "0123456789abcdef",
+	"symbols": "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!#%*+-.:=?@_~",
+}
+
+// GenerateSecret returns a random secret of length characters taken from charset with crypto/rand
+func GenerateSecret(length int, charset string) (string, error) {
+	if named, ok := Charsets[charset]; ok {
+		charset = named
+	}
+	chars := []rune(charset)
+	if len(chars) < 2 {
+		return "", fmt.Errorf("the charset needs at least 2 characters")
+	}
+	if length <= 0 {
+		return "", fmt.Errorf("invalid length %d", length)
+	}
+
+	secret := make([]rune, length)
+	max := big.NewInt(int64(len(chars)))
+	for i := range secret {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		secret[i] = chars[n.Int64()]
+	}
+	return string(secret), nil
+}
+
+// ParseGeneratePlaceholder returns the length and charset of a !generate[:length[:charset]] placeholder.
+// ok is false if the value doesn't start with !generate, err is set if it does but is not a valid placeholder.
+func ParseGeneratePlaceholder(value string) (length int, charset string, ok bool, err error) {
+	if !strings.HasPrefix(value, GeneratePlaceholder) {
+		return 0, "", false, nil
+	}
+	if value != GeneratePlaceholder && !strings.HasPrefix(value, GeneratePlaceholder+":") {
+		return 0, "", true, fmt.Errorf("invalid placeholder %q, expected %s[:length[:charset]]", value, GeneratePlaceholder)
+	}
+	length, charset = DefaultSecretLength, "alnum"
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) > 1 && parts[1] != "" {
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n <= 0 {
+			return 0, "", true, fmt.Errorf("invalid placeholder %q, the length must be a positive number", value)
+		}
+		length = n
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		charset = parts[2]
+	}
+	if _, named := Charsets[charset]; !named && len([]rune(charset)) < 2 {
+		return 0, "", true, fmt.Errorf("invalid placeholder %q, the charset needs at least 2 characters", value)
+	}
+	return length, charset, true, nil
+}
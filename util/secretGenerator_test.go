@@ -0,0 +1,53 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret(32, "hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 32, len(secret))
+	assert.Equal(t, "", strings.Trim(secret, Charsets["hex"]))
+
+	other, _ := GenerateSecret(32, "hex")
+	assert.NotEqual(t, secret, other)
+
+	secret, err = GenerateSecret(10, "ab")
+	assert.Nil(t, err)
+	assert.Equal(t, "", strings.Trim(secret, "ab"))
+
+	_, err = GenerateSecret(10, "a")
+	assert.NotNil(t, err)
+	_, err = GenerateSecret(0, "alnum")
+	assert.NotNil(t, err)
+}
+
+func TestParseGeneratePlaceholder(t *testing.T) {
+	length, charset, ok, err := ParseGeneratePlaceholder("!generate")
+	assert.Equal(t, true, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultSecretLength, length)
+	assert.Equal(t, "alnum", charset)
+
+	length, charset, ok, err = ParseGeneratePlaceholder("!generate:16:hex")
+	assert.Equal(t, true, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, 16, length)
+	assert.Equal(t, "hex", charset)
+
+	_, _, ok, err = ParseGeneratePlaceholder("generate:16")
+	assert.Equal(t, false, ok)
+	assert.Nil(t, err)
+
+	for _, value := range []string{"!generated", "!generate:many", "!generate:3two", "!generate:0", "!generate:8:a"} {
+		_, _, ok, err = ParseGeneratePlaceholder(value)
+		assert.Equal(t, true, ok, value)
+		assert.NotNil(t, err, value)
+	}
+}
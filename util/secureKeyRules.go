@@ -0,0 +1,74 @@
+package util
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultSecureKeyPatterns lists the key fragments that mark a parameter as SecureString
+var DefaultSecureKeyPatterns = []string{
+	"password",
+	"secret",
+	"dsn",
+	"apikey",
+	"api_key",
+	"token",
+	"privatekey",
+	"connectionstring",
+}
+
+// SecureKeyRules decides the parameter type of a configuration key
+type SecureKeyRules struct {
+	patterns []string
+}
+
+// NewSecureKeyRules create new rules, a pattern containing glob characters is matched against the whole key,
+// otherwise it is a case insensitive fragment of the last key segment
+func NewSecureKeyRules(patterns []string) *SecureKeyRules {
+	rules := &SecureKeyRules{}
+	for _, pattern := range patterns {
+		rules.patterns = append(rules.patterns, strings.ToLower(pattern))
+	}
+	return rules
+}
+
+// ParseSecureKeyRules reads one pattern per line, see ReadPatterns
+func ParseSecureKeyRules(data []byte) *SecureKeyRules {
+	return NewSecureKeyRules(ReadPatterns(data))
+}
+
+// ReadPatterns reads one pattern per line, blank lines and lines starting with # are skipped
+func ReadPatterns(data []byte) []string {
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns
+}
+
+// IsSecure returns true if the key matches one of the rules
+func (r *SecureKeyRules) IsSecure(key string) bool {
+	key = strings.ToLower(key)
+	segment := key[strings.LastIndex(key, "/")+1:]
+	for _, pattern := range r.patterns {
+		if strings.ContainsAny(pattern, "*?[") {
+			if matched, _ := path.Match(pattern, key); matched {
+				return true
+			}
+		} else if strings.Contains(segment, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeOf returns SecureString for keys matching the rules and String otherwise
+func (r *SecureKeyRules) TypeOf(key string) string {
+	if r.IsSecure(key) {
+		return "SecureString"
+	}
+	return "String"
+}
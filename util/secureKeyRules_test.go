@@ -0,0 +1,26 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultSecureKeyRules(t *testing.T) {
+	rules := NewSecureKeyRules(DefaultSecureKeyPatterns)
+
+	assert.Equal(t, "SecureString", rules.TypeOf("consolecredentials/password"))
+	assert.Equal(t, "SecureString", rules.TypeOf("OAuth2/ClientSecret"))
+	assert.Equal(t, "SecureString", rules.TypeOf("sentry/sentrydsn"))
+	assert.Equal(t, "SecureString", rules.TypeOf("payments/ApiKey"))
+	assert.Equal(t, "String", rules.TypeOf("secrets/endpoint"))
+	assert.Equal(t, "String", rules.TypeOf("webapp/port"))
+}
+
+func TestParseSecureKeyRules(t *testing.T) {
+	rules := ParseSecureKeyRules([]byte("# custom rules\n\ncertificate\nredis/*/password\n"))
+
+	assert.Equal(t, true, rules.IsSecure("tls/certificatepem"))
+	assert.Equal(t, true, rules.IsSecure("redis/cache/password"))
+	assert.Equal(t, false, rules.IsSecure("consolecredentials/password"))
+}
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stale parameter reasons
+const (
+	ReasonUnreferenced = "UNREFERENCED"
+	ReasonStaleProject = "STALE_PROJECT"
+)
+
+// StaleParameter is a parameter that is probably no longer used
+type StaleParameter struct {
+	ParameterMetadata
+	Reason  string
+	Project string
+}
+
+// ParseAge parses an age like 180d, 4w or any duration accepted by time.ParseDuration
+func ParseAge(age string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(age, suffix) {
+			count, err := strconv.Atoi(strings.TrimSuffix(age, suffix))
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid age %s", age)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(age)
+}
+
+// ProjectOf returns the first depth segments of a parameter name, or its parent path if it is shorter
+func ProjectOf(name string, depth int) string {
+	segments := strings.Split(strings.TrimPrefix(name, "/"), "/")
+	if len(segments) <= depth {
+		depth = len(segments) - 1
+	}
+	return "/" + strings.Join(segments[:depth], "/")
+}
+
+// FindStaleParameters returns the parameters not modified since cutoff that are either common parameters
+// no value points to, or that belong to a project with no parameter modified since cutoff.
+// references holds the names of the common parameters pointed to, projectDepth the segments of a project path.
+func FindStaleParameters(list []ParameterMetadata, references map[string]bool, cutoff time.Time, projectDepth int) []StaleParameter {
+	lastChange := make(map[string]time.Time)
+	for _, m := range list {
+		if strings.Contains(m.Name, "/common/") {
+			continue
+		}
+		project := ProjectOf(m.Name, projectDepth)
+		if m.LastModifiedDate.After(lastChange[project]) {
+			lastChange[project] = m.LastModifiedDate
+		}
+	}
+
+	var ret []StaleParameter
+	for _, m := range list {
+		if !m.LastModifiedDate.Before(cutoff) {
+			continue
+		}
+		if strings.Contains(m.Name, "/common/") {
+			if !references[m.Name] {
+				ret = append(ret, StaleParameter{ParameterMetadata: m, Reason: ReasonUnreferenced})
+			}
+			continue
+		}
+		project := ProjectOf(m.Name, projectDepth)
+		if lastChange[project].Before(cutoff) {
+			ret = append(ret, StaleParameter{ParameterMetadata: m, Reason: ReasonStaleProject, Project: project})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// WriteStaleReport writes the stale parameters as a CSV without header, so that the names of the first column
+// can be passed as they are to a delete step. The other columns are type, last modified date, reason and project.
+func WriteStaleReport(w io.Writer, stale []StaleParameter) error {
+	cw := csv.NewWriter(w)
+	for _, s := range stale {
+		cw.Write([]string{s.Name, s.Type, s.Column("modified"), s.Reason, s.Project})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAge(t *testing.T) {
+	age, err := ParseAge("180d")
+	assert.Nil(t, err)
+	assert.Equal(t, 180*24*time.Hour, age)
+
+	age, err = ParseAge("2w")
+	assert.Nil(t, err)
+	assert.Equal(t, 14*24*time.Hour, age)
+
+	age, err = ParseAge("36h")
+	assert.Nil(t, err)
+	assert.Equal(t, 36*time.Hour, age)
+
+	_, err = ParseAge("xd")
+	assert.NotNil(t, err)
+}
+
+func TestProjectOf(t *testing.T) {
+	assert.Equal(t, "/prod/dom/proj", ProjectOf("/prod/dom/proj/db/password", 3))
+	assert.Equal(t, "/prod/dom", ProjectOf("/prod/dom/flag", 3))
+}
+
+func TestFindStaleParameters(t *testing.T) {
+	old := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := []ParameterMetadata{
+		{Name: "/prod/common/redis/endpoint", Type: "String", LastModifiedDate: old},
+		{Name: "/prod/common/smtp/host", Type: "String", LastModifiedDate: old},
+		{Name: "/prod/common/kafka/brokers", Type: "String", LastModifiedDate: recent},
+		{Name: "/prod/dom/legacy/db/url", Type: "String", LastModifiedDate: old},
+		{Name: "/prod/dom/legacy/db/password", Type: "SecureString", LastModifiedDate: old},
+		{Name: "/prod/dom/proj/webapp/port", Type: "String", LastModifiedDate: old},
+		{Name: "/prod/dom/proj/log/level", Type: "String", LastModifiedDate: recent},
+	}
+	references := map[string]bool{"/prod/common/redis/endpoint": true}
+
+	stale := FindStaleParameters(list, references, cutoff, 3)
+
+	assert.Equal(t, 3, len(stale))
+	assert.Equal(t, "/prod/common/smtp/host", stale[0].Name)
+	assert.Equal(t, ReasonUnreferenced, stale[0].Reason)
+	assert.Equal(t, "/prod/dom/legacy/db/password", stale[1].Name)
+	assert.Equal(t, ReasonStaleProject, stale[1].Reason)
+	assert.Equal(t, "/prod/dom/legacy", stale[1].Project)
+	assert.Equal(t, "/prod/dom/legacy/db/url", stale[2].Name)
+
+	var buffer bytes.Buffer
+	err := WriteStaleReport(&buffer, stale[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "/prod/common/smtp/host,String,2019-01-01T00:00:00Z,UNREFERENCED,\n", buffer.String())
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"github.com/BurntSushi/toml"
+)
+
+type tomlToCsvConverter struct {
+	flattener
+}
+
+// NewTOMLToCsvConverter create a new converter for TOML files
+func NewTOMLToCsvConverter(options ...ConverterOption) converter {
+	return &tomlToCsvConverter{newFlattener(options)}
+}
+
+// Convert return a slice of strings with converted data
+func (c *tomlToCsvConverter) Convert(inputTOML []byte) ([]string, error) {
+	rows, err := c.ConvertWithValues(inputTOML)
+	return keysOf(rows), err
+}
+
+// ConvertWithValues return a slice of keys with their default values
+func (c *tomlToCsvConverter) ConvertWithValues(inputTOML []byte) ([]KeyValue, error) {
+	tomlMap := map[string]interface{}{}
+	_, err := toml.Decode(string(inputTOML), &tomlMap)
+	if err != nil {
+		return nil, err
+	}
+	return c.getRows(nil, normalize(tomlMap).(map[string]interface{})), nil
+}
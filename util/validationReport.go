@@ -0,0 +1,194 @@
+package util
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Validation states and actions
+const (
+	StateMissing      = "MISSING"
+	StatePresent      = "PRESENT"
+	ActionCreate      = "CREATE"
+	ActionDuplicate   = "DUPLICATE"
+	ActionMaintain    = "MAINTAIN"
+	ActionDestructive = "DESTRUCTIVE"
+	ActionOverwrite   = "OVERWRITE"
+)
+
+// MaskedValue replaces secure values in reports and shell output
+const MaskedValue = "********"
+
+// ValidationResult describes what uploading a CSV row would do to the parameter store
+type ValidationResult struct {
+	State      string   `json:"state"`
+	Action     string   `json:"action"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	OldType    string   `json:"old_type,omitempty"`
+	OldValue   string   `json:"old_value,omitempty"`
+	NewValue   string   `json:"new_value"`
+	Message    string   `json:"message,omitempty"`
+	Duplicates []string `json:"duplicates,omitempty"`
+}
+
+// Status returns the state and action of the result, e.g. "PRESENT -> OVERWRITE"
+func (r ValidationResult) Status() string {
+	return r.State + " -> " + r.Action
+}
+
+// Blocking returns true if uploading the row would change or damage an existing value
+func (r ValidationResult) Blocking() bool {
+	return r.Action == ActionDestructive || r.Action == ActionOverwrite
+}
+
+// MaskValue hides the value of SecureString parameters
+func MaskValue(paramType string, value string) string {
+	if paramType == "SecureString" && value != "" {
+		return MaskedValue
+	}
+	return value
+}
+
+// ReportFormats lists the supported validation report formats
+var ReportFormats = map[string]bool{
+	"text":     true,
+	"json":     true,
+	"junit":    true,
+	"markdown": true,
+}
+
+// WriteValidationReport writes the validation results in the given format: text, json, junit or markdown.
+// Unless reveal is true SecureString values are masked, the old value according to the stored type.
+func WriteValidationReport(w io.Writer, format string, results []ValidationResult, reveal bool) error {
+	if !reveal {
+		masked := make([]ValidationResult, len(results))
+		for i, r := range results {
+			oldType := r.OldType
+			if oldType == "" {
+				oldType = r.Type
+			}
+			r.OldValue = MaskValue(oldType, r.OldValue)
+			r.NewValue = MaskValue(r.Type, r.NewValue)
+			masked[i] = r
+		}
+		results = masked
+	}
+	switch format {
+	case "", "text":
+		return writeTextReport(w, results)
+	case "json":
+		return writeJSONReport(w, results)
+	case "junit":
+		return writeJUnitReport(w, results)
+	case "markdown":
+		return writeMarkdownReport(w, results)
+	}
+	return fmt.Errorf("unknown report format %s", format)
+}
+
+func writeTextReport(w io.Writer, results []ValidationResult) error {
+	for _, r := range results {
+		line := fmt.Sprintf("%-22s - %s WITH VALUE %s", r.Status(), r.Name, r.NewValue)
+		if r.Message != "" {
+			line += " " + r.Message
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		for _, duplicate := range r.Duplicates {
+			if _, err := fmt.Fprintln(w, "- "+duplicate); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeJSONReport(w io.Writer, results []ValidationResult) error {
+	report := struct {
+		Blocking bool               `json:"blocking"`
+		Results  []ValidationResult `json:"results"`
+	}{Results: results}
+	if report.Results == nil {
+		report.Results = []ValidationResult{}
+	}
+	for _, r := range results {
+		report.Blocking = report.Blocking || r.Blocking()
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+func writeJUnitReport(w io.Writer, results []ValidationResult) error {
+	suite := junitTestSuite{Name: "pargolo validate", Tests: len(results)}
+	for _, r := range results {
+		detail := fmt.Sprintf("old value: %s\nnew value: %s", r.OldValue, r.NewValue)
+		testCase := junitTestCase{Name: r.Name, ClassName: r.Status()}
+		if r.Blocking() {
+			suite.Failures++
+			testCase.Failure = &junitFailure{Message: r.Status(), Type: r.Action, Text: detail}
+		} else {
+			testCase.SystemOut = detail
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func writeMarkdownReport(w io.Writer, results []ValidationResult) error {
+	lines := []string{
+		"|Status|Name|Old value|New value|",
+		"| --- | --- | --- | --- |",
+	}
+	for _, r := range results {
+		status := r.Status()
+		if r.Blocking() {
+			status = "**" + status + "**"
+		}
+		lines = append(lines, fmt.Sprintf("|%s|%s|%s|%s|", status, markdownCell(r.Name), markdownCell(r.OldValue), markdownCell(r.NewValue)))
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func markdownCell(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + strings.NewReplacer("|", "\\|", "`", "'", "\n", " ").Replace(value) + "`"
+}
@@ -0,0 +1,107 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testValidationResults = []ValidationResult{
+	{State: StateMissing, Action: ActionCreate, Name: "/dev/dom/proj/webapp/port", Type: "String", NewValue: "8080"},
+	{State: StatePresent, Action: ActionOverwrite, Name: "/dev/dom/proj/oauth2/clientsecret", Type: "SecureString", OldValue: "old", NewValue: "new"},
+}
+
+func TestJSONReport(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteValidationReport(&buffer, "json", testValidationResults, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := struct {
+		Blocking bool
+		Results  []ValidationResult
+	}{}
+	err = json.Unmarshal(buffer.Bytes(), &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, true, report.Blocking)
+	assert.Equal(t, testValidationResults[0], report.Results[0])
+	assert.Equal(t, MaskedValue, report.Results[1].OldValue)
+	assert.Equal(t, MaskedValue, report.Results[1].NewValue)
+	assert.Equal(t, "new", testValidationResults[1].NewValue)
+}
+
+func TestRevealedReport(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteValidationReport(&buffer, "markdown", testValidationResults, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, true, strings.Contains(buffer.String(), "|`old`|`new`|"))
+}
+
+func TestSecureStringChangedToString(t *testing.T) {
+	results := []ValidationResult{
+		{State: StatePresent, Action: ActionOverwrite, Name: "/dev/dom/proj/db/password", Type: "String", OldType: "SecureString", OldValue: "secret", NewValue: "plain"},
+	}
+
+	for _, format := range []string{"text", "json", "junit", "markdown"} {
+		var buffer bytes.Buffer
+		err := WriteValidationReport(&buffer, format, results, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, false, strings.Contains(buffer.String(), "secret"), format)
+		assert.Equal(t, true, strings.Contains(buffer.String(), "plain"), format)
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteValidationReport(&buffer, "junit", testValidationResults, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := buffer.String()
+
+	assert.Equal(t, true, strings.Contains(actual, `<testsuite name="pargolo validate" tests="2" failures="1">`))
+	assert.Equal(t, true, strings.Contains(actual, `<failure message="PRESENT -&gt; OVERWRITE" type="OVERWRITE">`))
+	assert.Equal(t, false, strings.Contains(actual, "old\n"))
+	assert.Equal(t, false, strings.Contains(actual, "new value: new"))
+}
+
+func TestMarkdownReport(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteValidationReport(&buffer, "markdown", testValidationResults, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+
+	assert.Equal(t, 4, len(lines))
+	assert.Equal(t, "|MISSING -> CREATE|`/dev/dom/proj/webapp/port`||`8080`|", lines[2])
+	assert.Equal(t, "|**PRESENT -> OVERWRITE**|`/dev/dom/proj/oauth2/clientsecret`|`********`|`********`|", lines[3])
+}
+
+func TestTextReport(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteValidationReport(&buffer, "text", testValidationResults[:1], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "MISSING -> CREATE      - /dev/dom/proj/webapp/port WITH VALUE 8080\n", buffer.String())
+}
+
+func TestUnknownReportFormatShouldReturnError(t *testing.T) {
+	err := WriteValidationReport(&bytes.Buffer{}, "html", testValidationResults, false)
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"gopkg.in/yaml.v2"
+)
+
+type yamlToCsvConverter struct {
+	flattener
+}
+
+// NewYAMLToCsvConverter create a new converter for YAML files such as Spring application.yml
+func NewYAMLToCsvConverter(options ...ConverterOption) converter {
+	return &yamlToCsvConverter{newFlattener(options)}
+}
+
+// Convert return a slice of strings with converted data
+func (c *yamlToCsvConverter) Convert(inputYAML []byte) ([]string, error) {
+	rows, err := c.ConvertWithValues(inputYAML)
+	return keysOf(rows), err
+}
+
+// ConvertWithValues return a slice of keys with their default values
+func (c *yamlToCsvConverter) ConvertWithValues(inputYAML []byte) ([]KeyValue, error) {
+	yamlMap := map[string]interface{}{}
+	err := yaml.Unmarshal(inputYAML, &yamlMap)
+	if err != nil {
+		return nil, err
+	}
+	return c.getRows(nil, normalize(yamlMap).(map[string]interface{})), nil
+}